@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"schedule"
+)
+
+const NO_ATTRIBUTE = "(none)"
+
+var attributes stringList
+var whereFilter stringList
+
+// setAttributes sets the attributes given by --attr on the item.
+func setAttributes(item *schedule.ScheduleItem) {
+	for _,attribute := range attributes {
+		err := item.SetAttributeString(attribute)
+		fatalErrorf(err,"Invalid --attr %s",attribute)
+	}
+}
+
+// matchWhereFilter tells whether the item has all the attribute values
+// given by --where.
+func matchWhereFilter(item *schedule.ScheduleItem) bool {
+	for _,where := range whereFilter {
+		pair := strings.SplitN(where,"=",2)
+		fatalTruef(len(pair) != 2,"Invalid --where %s, expect attr=value",where)
+		value,ok := item.Attribute(pair[0])
+		if !ok || value != pair[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// statByAttribute counts the time of every value of the attribute.
+func statByAttribute(startDay,toDay,key string) {
+	fatalFalsef(isWord(key),"Invalid statistic dimension: %s",key)
+	statByLabels(startDay,toDay,key,func (item *schedule.ScheduleItem) []string {
+		value,ok := item.Attribute(key)
+		if !ok {
+			value = NO_ATTRIBUTE
+		}
+		return []string{value}
+	})
+}
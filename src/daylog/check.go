@@ -0,0 +1,332 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"flag"
+	"sort"
+	"regexp"
+	"strconv"
+	"strings"
+	"schedule"
+	"io/ioutil"
+	"path/filepath"
+)
+
+var fix bool
+
+type Checker struct {
+	problems int
+	fixed int
+}
+
+type checkedItem struct {
+	file string
+	line int
+	item *schedule.ScheduleItem
+}
+
+func NewChecker() *Checker {
+	return &Checker{0,0}
+}
+
+func (checker *Checker) Report(file string,line int,s string,v ...interface{}) {
+	checker.problems += 1
+	if line > 0 {
+		fmt.Printf("%s:%d: %s\n",file,line,fmt.Sprintf(s,v...))
+	} else {
+		fmt.Printf("%s: %s\n",file,fmt.Sprintf(s,v...))
+	}
+}
+
+// Fixed marks the problems reported since before as fixed.
+func (checker *Checker) Fixed(before int,file string,s string,v ...interface{}) {
+	checker.fixed += checker.problems-before
+	checker.problems = before
+	fmt.Printf("%s: fixed: %s\n",file,fmt.Sprintf(s,v...))
+}
+
+func check() {
+	if flag.NArg() > 1 {
+		checkUsage()
+	}
+	checker := NewChecker()
+	items := []*checkedItem{}
+	for _,day := range dayFiles() {
+		items = append(items,checker.CheckDayFile(day)...)
+	}
+	if checker.fixed > 0 {
+		items = checkedItemsAfterFix()
+	}
+	checker.CheckOverlaps(items)
+	for _,slot := range runningSlots() {
+		checker.CheckStartFile(startPathOf(slot))
+	}
+	checker.CheckSettingFile(filepath.Join(path,SETTING_FILE))
+	checker.CheckTaskFile(filepath.Join(path,TASK_FILE))
+	if checker.fixed > 0 {
+		fmt.Printf("%d problems fixed\n",checker.fixed)
+	}
+	if checker.problems > 0 {
+		fmt.Printf("%d problems found\n",checker.problems)
+		os.Exit(1)
+	}
+	fmt.Printf("No problems found\n")
+}
+
+// checkedItemsAfterFix reads the items of the day files again once the fixes
+// are written, as items may have been moved to other files and lines
+// renumbered.
+func checkedItemsAfterFix() []*checkedItem {
+	items := []*checkedItem{}
+	for _,day := range dayFiles() {
+		filename := filepath.Join(path,day)
+		lines,_ := readLines(filename)
+		seen := make(map[string]bool)
+		for i,line := range lines {
+			item,err := schedule.ScheduleItemFromString(line)
+			if err == nil && item.Finish() != nil && !seen[item.String()] {
+				seen[item.String()] = true
+				items = append(items,&checkedItem{filename,i+1,item})
+			}
+		}
+	}
+	return items
+}
+
+// dayFiles lists the days that have a day file, in order.
+func dayFiles() []string {
+	files,err := ioutil.ReadDir(path)
+	if os.IsNotExist(err) {
+		return []string{}
+	}
+	fatalError("Error reading directory "+path,err)
+	days := []string{}
+	for _,file := range files {
+		if !file.IsDir() && schedule.IsDayString(file.Name()) {
+			days = append(days,file.Name())
+		}
+	}
+	sort.Strings(days)
+	return days
+}
+
+func readLines(filename string) ([]string,bool) {
+	data,err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return []string{},false
+	}
+	fatalError("Error reading "+filename,err)
+	return strings.Split(string(data),"\n"),true
+}
+
+// diagnoseItem explains why an item line failed to parse.
+func diagnoseItem(line string,err error) (string,bool) {
+	fields := strings.SplitN(line," ",3)
+	if len(fields) >= 2 {
+		start,err1 := schedule.ParseTime(fields[0])
+		finish,err2 := schedule.ParseTime(fields[1])
+		if err1 == nil && err2 == nil && !finish.After(start) {
+			return "zero or negative duration",start.Equal(finish)
+		}
+	}
+	return err.Error(),false
+}
+
+// CheckDayFile reports the problems of a day file. With --fix, items filed
+// under a wrong day are moved, duplicated and empty items are dropped and
+// the items are sorted, unless some line cannot be read at all.
+func (checker *Checker) CheckDayFile(day string) []*checkedItem {
+	filename := filepath.Join(path,day)
+	lines,_ := readLines(filename)
+	items := []*checkedItem{}
+	kept := schedule.NewScheduleGroup()
+	moved := []*schedule.ScheduleItem{}
+	seen := make(map[string]bool)
+	fixable,changed := true,false
+	before := checker.problems
+	last := ""
+	var noted *schedule.ScheduleItem
+	for i,line := range lines {
+		if line == "" {
+			continue
+		}
+		if schedule.IsNoteLine(line) || schedule.IsAttributeLine(line) {
+			if noted == nil {
+				checker.Report(filename,i+1,"note or attributes without item")
+				fixable = false
+			} else if schedule.IsNoteLine(line) {
+				noted.AddNote(schedule.NoteOfLine(line))
+			} else if err := noted.SetAttributeString(schedule.AttributesOfLine(line)); err != nil {
+				checker.Report(filename,i+1,"%s",err.Error())
+				fixable = false
+			}
+			continue
+		}
+		noted = nil
+		item,err := schedule.ScheduleItemFromString(line)
+		if err != nil {
+			message,empty := diagnoseItem(line,err)
+			checker.Report(filename,i+1,"%s",message)
+			fixable = fixable && empty
+			changed = true
+			continue
+		}
+		if item.Finish() == nil {
+			checker.Report(filename,i+1,"missing finish time")
+			fixable = false
+			continue
+		}
+		if seen[item.String()] {
+			checker.Report(filename,i+1,"duplicated item")
+			changed = true
+			continue
+		}
+		seen[item.String()] = true
+		noted = item
+		items = append(items,&checkedItem{filename,i+1,item})
+		if item.StartDayString() != day {
+			checker.Report(filename,i+1,"item starts on %s",item.StartDayString())
+			moved = append(moved,item)
+			changed = true
+			continue
+		}
+		if last != "" && schedule.CompareTimeString(last,item.StartString()) > 0 {
+			checker.Report(filename,i+1,"item out of order")
+			changed = true
+		}
+		last = item.StartString()
+		kept.Add(item)
+	}
+	if !fix || !fixable || !changed {
+		return items
+	}
+	targets,days,ok := readMoveTargets(filename,moved)
+	if !ok {
+		return items
+	}
+	for _,item := range moved {
+		targets[item.StartDayString()].Add(item)
+	}
+	// The items are written to their days before they leave this one, so
+	// that none is lost on the way.
+	writeScheduleGroups(targets,days)
+	kept.Sort()
+	WriteFile(filename,kept.StringOfDay(day))
+	checker.Fixed(before,filename,"rewritten with %d items",kept.Size())
+	return items
+}
+
+// readMoveTargets reads the day files the misfiled items are moved to. A day
+// file that cannot be read leaves the items where they are.
+func readMoveTargets(filename string,moved []*schedule.ScheduleItem) (map[string]*schedule.ScheduleGroup,[]string,bool) {
+	targets := make(map[string]*schedule.ScheduleGroup)
+	days := []string{}
+	for _,item := range moved {
+		day := item.StartDayString()
+		if _,ok := targets[day]; ok {
+			continue
+		}
+		scheduleGroup,err := schedule.ScheduleGroupFromPossibleFile(filepath.Join(path,day))
+		if err != nil {
+			fmt.Printf("%s: not fixed: cannot move items to %s: %s\n",filename,day,err.Error())
+			return nil,nil,false
+		}
+		targets[day] = scheduleGroup
+		days = append(days,day)
+	}
+	return targets,days,true
+}
+
+func (checker *Checker) CheckOverlaps(items []*checkedItem) {
+	sort.SliceStable(items,func (i,j int) bool {
+		return items[i].item.Start().Before(*items[j].item.Start())
+	})
+	var latest *checkedItem
+	for _,checked := range items {
+		if latest != nil && checked.item.Overlaps(latest.item) {
+			checker.Report(checked.file,checked.line,"overlaps with %s:%d",latest.file,latest.line)
+		}
+		if latest == nil || checked.item.Finish().After(*latest.item.Finish()) {
+			latest = checked
+		}
+	}
+}
+
+func (checker *Checker) CheckStartFile(filename string) {
+	data,err := ioutil.ReadFile(filename)
+	fatalError("Error reading "+filename,err)
+	item,err := schedule.RunningItemFromString(string(data))
+	if err != nil {
+		checker.Report(filename,0,"%s",err.Error())
+		return
+	}
+	if item.Finish() != nil {
+		checker.Report(filename,1,"running item has finish time")
+	}
+}
+
+func (checker *Checker) CheckSettingFile(filename string) {
+	lines,ok := readLines(filename)
+	if !ok {
+		return
+	}
+	currentGroup := "global"
+	for i,c := range lines {
+		line := parseComment(c)
+		if line == "" {
+			continue
+		}
+		key,value := parseSpecialKeyValue(line)
+		if key == "color" && value != "" {
+			if _,ok := colorMap[value]; !ok {
+				checker.Report(filename,i+1,"unknown color '%s' in group %s",value,currentGroup)
+			}
+			continue
+		}
+		if key == "daily_target" || key == "weekly_target" {
+			if _,err := parseTarget(value); err != nil {
+				checker.Report(filename,i+1,"%s in group %s",err.Error(),currentGroup)
+			}
+			continue
+		}
+		if key == "priority" {
+			if _,err := strconv.Atoi(value); err != nil {
+				checker.Report(filename,i+1,"invalid priority '%s' in group %s",value,currentGroup)
+			}
+			continue
+		}
+		if key == "pattern" || key == "exclude" {
+			if _,err := regexp.Compile(value); err != nil {
+				checker.Report(filename,i+1,"invalid pattern in group %s: %s",currentGroup,err.Error())
+			}
+			continue
+		}
+		if key != "" {
+			continue
+		}
+		label := parseGroupLabel(line)
+		if label != "" {
+			currentGroup = label
+			continue
+		}
+		checker.Report(filename,i+1,"invalid setting: %s",line)
+	}
+}
+
+func (checker *Checker) CheckTaskFile(filename string) {
+	lines,ok := readLines(filename)
+	if !ok {
+		return
+	}
+	for i,t := range lines {
+		line := parseComment(t)
+		if line == "" {
+			continue
+		}
+		name,_,_ := parseTask(line)
+		if name == "" {
+			checker.Report(filename,i+1,"invalid task: %s",line)
+		}
+	}
+}
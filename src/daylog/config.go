@@ -2,8 +2,11 @@ package main
 
 import (
 	"os"
-	"log"
 	"flag"
+	"time"
+	"sort"
+	"strings"
+	"schedule"
 	"path/filepath"
 )
 
@@ -13,6 +16,7 @@ const (
 	SETTING_FILE = "settings"
 	START_FILE = "start"
 	TASK_FILE = "task"
+	DEFAULT_SLOT = "default"
 )
 
 var verboseLevel int
@@ -20,6 +24,8 @@ var verbose bool
 var colorScheme string
 var path string
 var startPath string
+var slot string
+var planFor time.Duration
 
 var configuration map[string]string
 var tasks *TaskSet
@@ -30,7 +36,36 @@ func setPath() {
 		path = EvalPath(DEFAULT_PATH)
 	}
 	Verbose(1,"Base path set to: %s\n",path)
-	startPath = filepath.Join(path,START_FILE)
+	startPath = startPathOf(slot)
+}
+
+func startPathOf(slot string) string {
+	if slot == "" || slot == DEFAULT_SLOT {
+		return filepath.Join(path,START_FILE)
+	}
+	return filepath.Join(path,START_FILE+"."+slot)
+}
+
+func slotOfStartPath(p string) string {
+	name := filepath.Base(p)
+	if name == START_FILE {
+		return DEFAULT_SLOT
+	}
+	return strings.TrimPrefix(name,START_FILE+".")
+}
+
+func runningSlots() []string {
+	slots := []string{}
+	if _,err := os.Stat(startPathOf(DEFAULT_SLOT)); err == nil {
+		slots = append(slots,DEFAULT_SLOT)
+	}
+	matches,err := filepath.Glob(filepath.Join(path,START_FILE+".*"))
+	fatalError("Error listing start files",err)
+	sort.Strings(matches)
+	for _,match := range matches {
+		slots = append(slots,slotOfStartPath(match))
+	}
+	return slots
 }
 
 func readConfig() {
@@ -53,6 +88,15 @@ func readConfig() {
 	}
 }
 
+func setZone() {
+	timezone := configuration["timezone"]
+	err := schedule.SetZone(timezone)
+	fatalErrorf(err,"Invalid timezone '%s' in %s",timezone,CONFIG_FILE)
+	if timezone != "" {
+		Verbose(1,"Timezone set to: %s\n",timezone)
+	}
+}
+
 func readSetting() {
 	settingGroups = make(map[string]*SettingGroup)
 	currentGroup := "global"
@@ -81,14 +125,15 @@ func readSetting() {
 			currentGroup = label
 			continue
 		}
-		log.Fatalf("Invalid setting in '%s:%d'",SETTING_FILE,i+1)
+		logFatalf("Invalid setting in '%s:%d'",SETTING_FILE,i+1)
 	}
+	linkSettingGroups(settingGroups)
 }
 
 func saveSetting() {
 	settingPath := filepath.Join(path,SETTING_FILE)
 	settings := ""
-	for _,group := range serializedSettingGroups(settingGroups) {
+	for _,group := range orderedSettingGroups(settingGroups) {
 		settings += group.String()
 	}
 	WriteFile(settingPath,settings)
@@ -136,4 +181,98 @@ func parseGlobalOptions() {
 	if verbose && verboseLevel == 0{
 		verboseLevel = 1
 	}
+
+	parseCommandOptions()
+}
+
+// parseCommandOptions picks the options of the command out of the arguments,
+// wherever they appear, so that flag.Arg() only sees the positional ones.
+func parseCommandOptions() {
+	if flag.NArg() < 1 {
+		return
+	}
+	command := flag.Arg(0)
+	errorHandling := flag.ExitOnError
+	if command == "status" {
+		// Errors must not look like the exit codes of status.
+		fatalExitCode = STATUS_ERROR
+		errorHandling = flag.ContinueOnError
+	}
+	options := flag.NewFlagSet(command,errorHandling)
+	switch command {
+	case "start":
+		options.StringVar(&slot,"slot","","Name of the slot of the running job")
+		options.DurationVar(&planFor,"for",0,"Planned length of the job, e.g. 45m")
+		options.Var(&attributes,"attr","Attribute of the job as key=value, may be repeated")
+	case "finish":
+		options.StringVar(&slot,"slot","","Name of the slot of the running job")
+		options.StringVar(&note,"note","","Note of what the job produced")
+		options.Var(&attributes,"attr","Attribute of the job as key=value, may be repeated")
+	case "restart","cancel","pause","resume","note":
+		options.StringVar(&slot,"slot","","Name of the slot of the running job")
+	case "pomodoro":
+		options.StringVar(&slot,"slot","","Name of the slot of the running job")
+		options.DurationVar(&pomodoroWork,"work",DEFAULT_POMODORO_WORK,"Length of a work interval")
+		options.DurationVar(&pomodoroBreak,"break",DEFAULT_POMODORO_BREAK,"Length of a break")
+		options.IntVar(&pomodoroCycles,"cycles",DEFAULT_POMODORO_CYCLES,"Number of work intervals")
+	case "status":
+		options.StringVar(&slot,"slot","","Name of the slot of the running job")
+		options.StringVar(&format,"format","","Template of the output, e.g. '{{.Content}} {{.Elapsed}}'")
+	case "stat","statistic","job","jobstat":
+		options.BoolVar(&jsonOutput,"json",false,"Output in json")
+		options.Var(&tagFilter,"tag","Only count jobs with the tag, may be repeated")
+		options.StringVar(&statBy,"by","group","Count time by: group, tag, or an attribute")
+	case "list":
+		options.Var(&tagFilter,"tag","Only list jobs with the tag, may be repeated")
+		options.BoolVar(&showNotes,"notes",false,"Show the notes of the jobs")
+		options.Var(&whereFilter,"where","Only list jobs with the attribute value as attr=value, may be repeated")
+		options.StringVar(&search,"search","","Only list jobs whose content or note matches the pattern")
+	case "search":
+		options.BoolVar(&fixedSearch,"fixed",false,"Search for the text as it is instead of a regular expression")
+		options.BoolVar(&showNotes,"notes",false,"Show the notes of the jobs")
+	case "export":
+		options.StringVar(&format,"format","json","Output format: json, csv, timeclock, timewarrior")
+	case "import":
+		options.StringVar(&format,"format","csv","Input format: csv, timeclock, timewarrior")
+	case "check":
+		options.BoolVar(&fix,"fix",false,"Repair the problems that can be repaired safely")
+	}
+	args := []string{command}
+	rest := flag.Args()[1:]
+	for len(rest) > 0 {
+		arg := rest[0]
+		if arg == "--" {
+			args = append(args,rest[1:]...)
+			break
+		}
+		if !isOption(arg) {
+			args = append(args,arg)
+			rest = rest[1:]
+			continue
+		}
+		n := 1
+		name := strings.TrimLeft(arg,"-")
+		if !strings.Contains(name,"=") {
+			option := options.Lookup(name)
+			if option != nil && !isBoolOption(option) && len(rest) > 1 {
+				n = 2
+			}
+		}
+		if err := options.Parse(rest[:n]); err != nil {
+			os.Exit(fatalExitCode)
+		}
+		rest = rest[n:]
+	}
+	flag.CommandLine.Parse(append([]string{"--"},args...))
+	fatalFalsef(slot == "" || isWord(slot),"Invalid slot name: %s",slot)
+}
+
+// isOption tells an option from a positional argument; -3d is a day.
+func isOption(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-' && (arg[1] < '0' || arg[1] > '9')
+}
+
+func isBoolOption(option *flag.Flag) bool {
+	b,ok := option.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
 }
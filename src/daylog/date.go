@@ -0,0 +1,175 @@
+package main
+
+import (
+	"flag"
+	"time"
+	"regexp"
+	"strings"
+	"strconv"
+	"schedule"
+)
+
+const DEFAULT_WEEK_START = time.Monday
+
+var relativeDayPattern = regexp.MustCompile("^-(\\d+)([dw])$")
+var monthPattern = regexp.MustCompile("^(\\d\\d\\d\\d)\\.(\\d\\d)$")
+var yearPattern = regexp.MustCompile("^(\\d\\d\\d\\d)$")
+var weekPattern = regexp.MustCompile("^(\\d\\d\\d\\d)\\.[wW](\\d\\d?)$")
+
+var weekdays = map[string]time.Weekday{
+	"sunday":time.Sunday,"monday":time.Monday,"tuesday":time.Tuesday,
+	"wednesday":time.Wednesday,"thursday":time.Thursday,"friday":time.Friday,
+	"saturday":time.Saturday,
+	"sun":time.Sunday,"mon":time.Monday,"tue":time.Tuesday,"wed":time.Wednesday,
+	"thu":time.Thursday,"fri":time.Friday,"sat":time.Saturday,
+}
+
+// weekStartFromConfiguration is the first day of a week, set by week_start
+// in the configuration.
+func weekStartFromConfiguration() time.Weekday {
+	name := strings.ToLower(configuration["week_start"])
+	if name == "" {
+		return DEFAULT_WEEK_START
+	}
+	weekday,ok := weekdays[name]
+	fatalFalsef(ok,"Invalid week_start in %s: %s",CONFIG_FILE,name)
+	return weekday
+}
+
+// calendarDay reads a day string as a date for calendar arithmetic.
+func calendarDay(day string) time.Time {
+	t,err := time.Parse(schedule.FORMAT_DAY,day)
+	fatalError("Invalid day "+day,err)
+	return t
+}
+
+func calendarDayString(t time.Time) string {
+	return t.Format(schedule.FORMAT_DAY)
+}
+
+func weekOf(t time.Time,weekStart time.Weekday) (from,to time.Time) {
+	offset := (int(t.Weekday())-int(weekStart)+7)%7
+	from = t.AddDate(0,0,-offset)
+	return from,from.AddDate(0,0,6)
+}
+
+func monthOf(t time.Time) (from,to time.Time) {
+	from = time.Date(t.Year(),t.Month(),1,0,0,0,0,time.UTC)
+	return from,from.AddDate(0,1,-1)
+}
+
+func yearOf(t time.Time) (from,to time.Time) {
+	from = time.Date(t.Year(),1,1,0,0,0,0,time.UTC)
+	return from,from.AddDate(1,0,-1)
+}
+
+// numberedWeek finds week n of a year. Week 1 is the week holding January
+// 4th, which makes the weeks the ISO weeks when they start on Monday. A year
+// has 52 or 53 weeks.
+func numberedWeek(year,n int,weekStart time.Weekday) (from,to time.Time,ok bool) {
+	from,_ = weekOf(time.Date(year,1,4,0,0,0,0,time.UTC),weekStart)
+	next,_ := weekOf(time.Date(year+1,1,4,0,0,0,0,time.UTC),weekStart)
+	from = from.AddDate(0,0,7*(n-1))
+	if n < 1 || !from.Before(next) {
+		return from,from,false
+	}
+	return from,from.AddDate(0,0,6),true
+}
+
+// evalRelativeDay evaluates the day expressions relative to today: -3d,
+// -2w and last monday.
+func evalRelativeDay(day string) (string,bool) {
+	return relativeDay(day,calendarDay(schedule.GetTodayString()))
+}
+
+func relativeDay(day string,today time.Time) (string,bool) {
+	if groups := relativeDayPattern.FindStringSubmatch(day); groups != nil {
+		n,_ := strconv.Atoi(groups[1])
+		if groups[2] == "w" {
+			n *= 7
+		}
+		return calendarDayString(today.AddDate(0,0,-n)),true
+	}
+	fields := strings.Fields(strings.ToLower(day))
+	if len(fields) == 2 && fields[0] == "last" {
+		weekday,ok := weekdays[fields[1]]
+		if !ok {
+			return "",false
+		}
+		t := today.AddDate(0,0,-1)
+		for t.Weekday() != weekday {
+			t = t.AddDate(0,0,-1)
+		}
+		return calendarDayString(t),true
+	}
+	return "",false
+}
+
+// evalDayRange evaluates a day expression to the first and the last day it
+// covers. Besides single days it takes this/last week, month and year, a
+// month like 2017.03, a year like 2017 and a week like 2017.W14. A range
+// that reaches beyond today ends today.
+func evalDayRange(s string) (start,to string,ok bool) {
+	if day,ok := evalDay(s); ok {
+		return day,day,true
+	}
+	return dayRange(s,calendarDay(schedule.GetTodayString()),weekStartFromConfiguration())
+}
+
+func dayRange(s string,today time.Time,weekStart time.Weekday) (start,to string,ok bool) {
+	var from,until time.Time
+	expression := strings.Join(strings.Fields(strings.ToLower(s))," ")
+	switch expression {
+	case "this week":
+		from,until = weekOf(today,weekStart)
+	case "last week":
+		from,until = weekOf(today.AddDate(0,0,-7),weekStart)
+	case "this month":
+		from,until = monthOf(today)
+	case "last month":
+		from,until = monthOf(today.AddDate(0,0,-today.Day()))
+	case "this year":
+		from,until = yearOf(today)
+	case "last year":
+		from,until = yearOf(today.AddDate(0,0,-today.YearDay()))
+	default:
+		if groups := monthPattern.FindStringSubmatch(s); groups != nil {
+			year,_ := strconv.Atoi(groups[1])
+			month,_ := strconv.Atoi(groups[2])
+			if month < 1 || month > 12 {
+				return "","",false
+			}
+			from,until = monthOf(time.Date(year,time.Month(month),1,0,0,0,0,time.UTC))
+		} else if groups := yearPattern.FindStringSubmatch(s); groups != nil {
+			year,_ := strconv.Atoi(groups[1])
+			from,until = yearOf(time.Date(year,1,1,0,0,0,0,time.UTC))
+		} else if groups := weekPattern.FindStringSubmatch(s); groups != nil {
+			year,_ := strconv.Atoi(groups[1])
+			week,_ := strconv.Atoi(groups[2])
+			if from,until,ok = numberedWeek(year,week,weekStart); !ok {
+				return "","",false
+			}
+		} else {
+			return "","",false
+		}
+	}
+	if until.After(today) && !from.After(today) {
+		until = today
+	}
+	return calendarDayString(from),calendarDayString(until),true
+}
+
+// dayArgs lists the day arguments of the command, joining "last monday" and
+// "this week" given without quotes.
+func dayArgs() []string {
+	args := []string{}
+	for i := 1; i < flag.NArg(); i++ {
+		arg := flag.Arg(i)
+		if (arg == "last" || arg == "this") && i+1 < flag.NArg() {
+			i += 1
+			arg += " "+flag.Arg(i)
+		}
+		args = append(args,arg)
+	}
+	return args
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"time"
+	"testing"
+)
+
+func TestDayRange(t *testing.T) {
+	today := time.Date(2026,10,14,0,0,0,0,time.UTC)
+	tests := []struct {
+		s string
+		weekStart time.Weekday
+		from string
+		to string
+		ok bool
+	}{
+		{"this week",time.Monday,"2026.10.12","2026.10.14",true},
+		{"this week",time.Sunday,"2026.10.11","2026.10.14",true},
+		{"last week",time.Monday,"2026.10.05","2026.10.11",true},
+		{"last week",time.Sunday,"2026.10.04","2026.10.10",true},
+		{"this month",time.Monday,"2026.10.01","2026.10.14",true},
+		{"last month",time.Monday,"2026.09.01","2026.09.30",true},
+		{"this year",time.Monday,"2026.01.01","2026.10.14",true},
+		{"last year",time.Monday,"2025.01.01","2025.12.31",true},
+		{"Last  Week",time.Monday,"2026.10.05","2026.10.11",true},
+		{"2017.03",time.Monday,"2017.03.01","2017.03.31",true},
+		{"2024.02",time.Monday,"2024.02.01","2024.02.29",true},
+		{"2026.12",time.Monday,"2026.12.01","2026.12.31",true},
+		{"2017",time.Monday,"2017.01.01","2017.12.31",true},
+		{"2017.W14",time.Monday,"2017.04.03","2017.04.09",true},
+		{"2026.W01",time.Monday,"2025.12.29","2026.01.04",true},
+		{"2026.W01",time.Sunday,"2026.01.04","2026.01.10",true},
+		{"2026.W42",time.Monday,"2026.10.12","2026.10.14",true},
+		{"2020.W53",time.Monday,"2020.12.28","2021.01.03",true},
+		{"2021.W53",time.Monday,"","",false},
+		{"2017.W54",time.Monday,"","",false},
+		{"2017.W00",time.Monday,"","",false},
+		{"2017.13",time.Monday,"","",false},
+		{"2017.00",time.Monday,"","",false},
+		{"next week",time.Monday,"","",false},
+	}
+	for _,test := range tests {
+		from,to,ok := dayRange(test.s,today,test.weekStart)
+		if ok != test.ok || from != test.from || to != test.to {
+			t.Errorf("dayRange(%q,%s) failed! Expect %s %s %v, got %s %s %v\n",
+				test.s,test.weekStart,test.from,test.to,test.ok,from,to,ok)
+		}
+	}
+}
+
+func TestRelativeDay(t *testing.T) {
+	today := time.Date(2026,3,2,0,0,0,0,time.UTC)
+	tests := []struct {
+		s string
+		day string
+		ok bool
+	}{
+		{"-0d","2026.03.02",true},
+		{"-3d","2026.02.27",true},
+		{"-2w","2026.02.16",true},
+		{"-1w","2026.02.23",true},
+		{"last monday","2026.02.23",true},
+		{"last sunday","2026.03.01",true},
+		{"last Fri","2026.02.27",true},
+		{"last funday","",false},
+		{"-3m","",false},
+		{"3d","",false},
+	}
+	for _,test := range tests {
+		day,ok := relativeDay(test.s,today)
+		if ok != test.ok || day != test.day {
+			t.Errorf("relativeDay(%q) failed! Expect %s %v, got %s %v\n",test.s,test.day,test.ok,day,ok)
+		}
+	}
+}
+
+func TestIsOption(t *testing.T) {
+	tests := []struct {
+		arg string
+		option bool
+	}{
+		{"--json",true},
+		{"-v",true},
+		{"-3d",false},
+		{"-",false},
+		{"today",false},
+	}
+	for _,test := range tests {
+		if isOption(test.arg) != test.option {
+			t.Errorf("isOption(%q) failed! Expect %v\n",test.arg,test.option)
+		}
+	}
+}
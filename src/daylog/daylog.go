@@ -27,23 +27,35 @@ func set() {
 	if flag.NArg() != 2 || (flag.NArg() > 1 && flag.Arg(1) == "help") {
 		setUsage()
 	}
-	name,key,value := parseGroupKeyValue(flag.Arg(1))
+	name,key,op,value := parseGroupKeyValue(flag.Arg(1))
 	fatalTrue(name == "" || key == "","Invalid group.key/value pair!")
 	if value == "" {
 		settingGroup,ok := settingGroups[name]
 		fatalFalsef(ok,"Group not exist: %s",name)
 		value,ok = settingGroup.get(key)
 		fatalFalsef(ok,"Invalid key: %s\n",key)
-		fmt.Printf("%s.%s: %s\n",name,key,value)
+		for _,v := range strings.Split(value,"\n") {
+			fmt.Printf("%s.%s: %s\n",name,key,v)
+		}
 	} else {
 		settingGroup,ok := settingGroups[name]
 		if !ok {
-			settingGroups[name] = NewSettingGroup(name)
+			tryAddingNewGroup(settingGroups,name)
 			settingGroup,_ = settingGroups[name]
 			Verbose(1,"Group %s not existed, created now\n",name)
 		}
-		settingGroup.set(key,value)
-		Verbose(1,"%s.%s is set to %s\n",name,key,value)
+		fatalTruef(op != "" && key != "pattern" && key != "exclude","Cannot add to or remove from %s",key)
+		if op == "-" {
+			fatalFalsef(settingGroup.remove(key,value),"No %s %s in group %s",key,value,name)
+			Verbose(1,"%s removed from %s.%s\n",value,name,key)
+		} else {
+			if op == "" {
+				settingGroup.reset(key)
+			}
+			settingGroup.set(key,value)
+			Verbose(1,"%s.%s is set to %s\n",name,key,value)
+		}
+		linkSettingGroups(settingGroups)
 		saveSetting()
 	}
 }
@@ -64,7 +76,7 @@ func start() {
 	fatalNotFileNotExistError(err)
 	if err == nil {
 		startString := strings.Trim(string(startFile),"\n")
-		item,err := schedule.ScheduleItemFromString(startString)
+		item,err := schedule.RunningItemFromString(startString)
 		if err == nil {
 			fmt.Printf("Task already started: %s\n",item.ContentString())
 			fmt.Printf("At Time: %s\n",item.StartString())
@@ -76,7 +88,14 @@ func start() {
 	fatalFalse(item.SetStartString(startTime),"Failed to set start time")
 	fmt.Printf("Started: %s\n",item.ContentString())
 	fmt.Printf("Time: %s\n",item.StartString())
-	WriteFile(startPath,item.String())
+	setAttributes(item)
+	if planFor > 0 {
+		planned := item.Start().Add(planFor)
+		fatalFalse(item.SetPlanned(&planned),"Failed to set planned finish time")
+		fmt.Printf("Until: %s\n",schedule.FormatTime(planned))
+	}
+	printSlot()
+	WriteFile(startPath,item.RunningString())
 }
 
 func restart() {
@@ -95,13 +114,17 @@ func restart() {
 	}
 	startFile,err := ioutil.ReadFile(startPath)
 	fatalNotFileNotExistError(err)
-	fatalFalse(err==nil,"No schedule started yet!")
+	fatalFalse(err==nil,noScheduleStarted())
 	startString := strings.Trim(string(startFile),"\n")
-	item,err := schedule.ScheduleItemFromString(startString)
+	item,err := schedule.RunningItemFromString(startString)
 	fatalError("Failed to parse schedule item",err)
 	fmt.Printf("Task already started: %s\n",item.ContentString())
 	fmt.Printf("At Time: %s\n",item.StartString())
 	if content == "" {
+		if start,err := schedule.GetTime(startTime); err == nil && item.Planned() != nil {
+			fatalFalsef(item.Planned().After(*start),"Start time %s is not before the planned finish time %s",
+				startTime,schedule.FormatTime(*item.Planned()))
+		}
 		fmt.Printf("Going to reset the start time to %s\n",startTime)
 		fmt.Printf("Proceed? (Y/n)")
 		ProceedOrExit(true)
@@ -113,7 +136,8 @@ func restart() {
 		item.SetContent(content)
 	}
 	fmt.Printf("Restarted: %s\nTime: %s\n",item.ContentString(),item.StartString())
-	WriteFile(startPath,item.String())
+	printSlot()
+	WriteFile(startPath,item.RunningString())
 }
 
 func cancel() {
@@ -122,10 +146,11 @@ func cancel() {
 	}
 	startFile,err := ioutil.ReadFile(startPath)
 	fatalNotFileNotExistError(err)
-	fatalFalse(err==nil,"No schedule started yet!")
+	fatalFalse(err==nil,noScheduleStarted())
 	startString := strings.Trim(string(startFile),"\n")
-	item,err := schedule.ScheduleItemFromString(startString)
+	item,err := schedule.RunningItemFromString(startString)
 	fatalError("Failed to parse schedule item",err)
+	printSlot()
 	fmt.Printf("Going to cancel task: %s\n",item.ContentString())
 	fmt.Printf("At Time: %s\nProceed? (Y/n)",item.StartString())
 	ProceedOrExit(true)
@@ -145,31 +170,240 @@ func finish() {
 	startFile,err := ioutil.ReadFile(startPath)
 	fatalNotFileNotExistError(err)
 	if err != nil {
+		fatalTrue(slot != "",noScheduleStarted())
 		prolongFinish(finishTime)
 		return
 	}
 	startString := strings.Trim(string(startFile),"\n")
-	item,err := schedule.ScheduleItemFromString(startString)
+	item,err := schedule.RunningItemFromString(startString)
 	fatalError("Start file corrupted: "+startPath,err)
+	printSlot()
 	fmt.Printf("Going to finish task: %s\n",item.ContentString())
 	fmt.Printf("Started at time: %s\nProceed? (Y/n)",item.StartString())
 	ProceedOrExit(true)
+	if flag.NArg() <= 1 {
+		finishTime = offerOverdueFinish(item,finishTime)
+	}
 	fmt.Printf("Going to finish at %s\n",finishTime)
+	item.AddNote(note)
+	setAttributes(item)
+	finishItem(item,finishTime)
+}
+
+// finishItem files the running item into the day files, finished at the
+// given time, and removes the start file.
+func finishItem(item *schedule.ScheduleItem,finishTime string) {
 	ok := item.SetFinishString(finishTime)
 	fatalFalse(ok,"Failed to set finish time!")
-	day := item.StartDayString()
-	schedulePath := filepath.Join(path,day)
-	scheduleGroup,err := schedule.ScheduleGroupFromPossibleFile(schedulePath)
-	fatalError("Error reading schedule file: "+schedulePath,err)
-	scheduleGroup.Add(item)
-	WriteFile(schedulePath,scheduleGroup.StringOfDay(day))
+	addScheduleItems(item.Segments())
 	duration,_ := item.DurationString()
 	fmt.Printf("Finished at time: %s\n",item.FinishString())
 	fmt.Printf("Duration: %s\n",duration)
-	err = os.Remove(startPath)
+	err := os.Remove(startPath)
 	fatalError("Error removing starting file",err)
 }
 
+func pause() {
+	if flag.NArg() > 2 || (flag.NArg() == 2 && flag.Arg(1) == "help") {
+		pauseUsage()
+	}
+	pauseTime := schedule.GetNowString()
+	if flag.NArg() > 1 {
+		pauseTime = ExpandTime(flag.Arg(1))
+	}
+	item := readRunningItem()
+	t,err := schedule.GetTime(pauseTime)
+	fatalError("Invalid pause time",err)
+	fatalError("Failed to pause",item.Pause(t))
+	fmt.Printf("Paused: %s\n",item.ContentString())
+	fmt.Printf("Time: %s\n",pauseTime)
+	printSlot()
+	WriteFile(startPath,item.RunningString())
+}
+
+func resume() {
+	if flag.NArg() > 2 || (flag.NArg() == 2 && flag.Arg(1) == "help") {
+		resumeUsage()
+	}
+	resumeTime := schedule.GetNowString()
+	if flag.NArg() > 1 {
+		resumeTime = ExpandTime(flag.Arg(1))
+	}
+	item := readRunningItem()
+	t,err := schedule.GetTime(resumeTime)
+	fatalError("Invalid resume time",err)
+	pausedSince := item.PausedSince()
+	fatalError("Failed to resume",item.Resume(t))
+	fmt.Printf("Resumed: %s\n",item.ContentString())
+	fmt.Printf("Time: %s\n",resumeTime)
+	fmt.Printf("Break: %dm\n",int(t.Sub(*pausedSince).Minutes()))
+	printSlot()
+	WriteFile(startPath,item.RunningString())
+}
+
+func add() {
+	if flag.NArg() != 4 {
+		addUsage()
+	}
+	readTasks()
+	content := getJobFromTask(flag.Arg(1))
+	startTime,finishTime := ExpandTime(flag.Arg(2)),ExpandTime(flag.Arg(3))
+	item := schedule.NewScheduleItem()
+	fatalFalsef(item.SetStartFinishString(startTime,finishTime),
+		"Invalid time range: %s to %s",startTime,finishTime)
+	item.SetContent(content)
+	firstDay,_ := schedule.DayAddString(item.StartDayString(),-1)
+	overlapped := make(map[string]*schedule.ScheduleGroup)
+	removed := []*schedule.ScheduleItem{}
+	for _,day := range RangeDay(firstDay,item.FinishDayString()) {
+		scheduleGroup := readScheduleGroupByDay(day)
+		for i := 0; i < scheduleGroup.Size(); i++ {
+			other,_ := scheduleGroup.Get(i)
+			if item.Overlaps(other) {
+				if len(overlapped) == 0 {
+					fmt.Printf("Overlapping items:\n")
+				}
+				overlapped[day] = scheduleGroup
+				other.Print()
+				if len(other.Subtract(item)) == 0 {
+					removed = append(removed,other)
+				}
+			}
+		}
+	}
+	if len(removed) > 0 {
+		fmt.Printf("Items to be removed entirely:\n")
+		for _,other := range removed {
+			other.Print()
+			other.PrintNote()
+		}
+	}
+	parts := []*schedule.ScheduleItem{}
+	if len(overlapped) > 0 {
+		fmt.Printf("Trim, split or remove the overlapping items? (y/N)")
+		ProceedOrExit(false)
+		for day,scheduleGroup := range overlapped {
+			kept := schedule.NewScheduleGroup()
+			for i := 0; i < scheduleGroup.Size(); i++ {
+				other,_ := scheduleGroup.Get(i)
+				if item.Overlaps(other) {
+					parts = append(parts,other.Subtract(item)...)
+				} else {
+					kept.Add(other)
+				}
+			}
+			WriteFile(filepath.Join(path,day),kept.StringOfDay(day))
+		}
+	}
+	addScheduleItems(append(parts,item))
+	duration,_ := item.DurationString()
+	fmt.Printf("Added: %s\n",item.ContentString())
+	fmt.Printf("From %s to %s\n",item.StartString(),item.FinishString())
+	fmt.Printf("Duration: %s\n",duration)
+}
+
+func edit() {
+	if flag.NArg() > 5 || (flag.NArg() == 2 && flag.Arg(1) == "help") {
+		editUsage()
+	}
+	day := "today"
+	if flag.NArg() > 1 {
+		day = flag.Arg(1)
+	}
+	day,ok := evalDay(day)
+	fatalFalsef(ok,"Invalid day: %s",flag.Arg(1))
+	scheduleGroup := readScheduleGroupByDay(day)
+	dayWithWeek,_ := schedule.GetDayWeekString(day)
+	if flag.NArg() <= 2 {
+		fmt.Printf("Day %s\n",dayWithWeek)
+		scheduleGroup.Print()
+		return
+	}
+	index,err := strconv.Atoi(flag.Arg(2))
+	fatalErrorf(err,"Invalid index '%s'",flag.Arg(2))
+	item,err := scheduleGroup.Get(index-1)
+	fatalErrorf(err,"No item %d in day %s",index,day)
+	operation,value := flag.Arg(3),flag.Arg(4)
+	fatalTrue(operation == "","Missing edit operation")
+	fatalTruef(operation != "delete" && flag.NArg() != 5,"Missing value for %s",operation)
+	fmt.Printf("Editing item %d of day %s:\n",index,dayWithWeek)
+	item.Print()
+	edited := []*schedule.ScheduleItem{item}
+	switch operation {
+	case "start":
+		startTime := ExpandTimeInDay(value,day)
+		fatalFalsef(item.SetStartString(startTime),"Invalid start time: %s",startTime)
+	case "finish":
+		finishTime := ExpandTimeInDay(value,day)
+		fatalFalsef(item.SetFinishString(finishTime),"Invalid finish time: %s",finishTime)
+	case "content":
+		readTasks()
+		item.SetContent(getJobFromTask(value))
+	case "attr":
+		err := item.SetAttributeString(value)
+		fatalError("Invalid attribute",err)
+	case "delete":
+		edited = []*schedule.ScheduleItem{}
+	case "split":
+		t,err := schedule.GetTime(ExpandTimeInDay(value,day))
+		fatalError("Invalid split time",err)
+		edited,err = item.SplitAt(t)
+		fatalError("Failed to split",err)
+	default:
+		editUsage()
+	}
+	scheduleGroup.RemoveIndex(index-1)
+	groups := map[string]*schedule.ScheduleGroup{day:scheduleGroup}
+	days := []string{day}
+	for _,e := range edited {
+		if _,ok := groups[e.StartDayString()]; !ok {
+			days = append(days,e.StartDayString())
+		}
+		readScheduleGroups(groups,e.StartDayString())
+	}
+	overlapped := []*schedule.ScheduleItem{}
+	seen := make(map[*schedule.ScheduleItem]bool)
+	for _,e := range edited {
+		firstDay,_ := schedule.DayAddString(e.StartDayString(),-1)
+		for _,d := range RangeDay(firstDay,e.FinishDayString()) {
+			readScheduleGroups(groups,d)
+			for i := 0; i < groups[d].Size(); i++ {
+				other,_ := groups[d].Get(i)
+				if e.Overlaps(other) && !seen[other] {
+					seen[other] = true
+					overlapped = append(overlapped,other)
+				}
+			}
+		}
+	}
+	overlapping := len(overlapped) > 0
+	if overlapping {
+		fmt.Printf("Overlaps with:\n")
+		for _,other := range overlapped {
+			other.Print()
+		}
+	}
+	if len(edited) == 0 {
+		fmt.Printf("Going to delete it.\n")
+	} else {
+		fmt.Printf("Going to update to:\n")
+		for _,e := range edited {
+			e.Print()
+		}
+	}
+	if overlapping {
+		fmt.Printf("Proceed with overlapping items? (y/N)")
+		ProceedOrExit(false)
+	} else {
+		fmt.Printf("Proceed? (Y/n)")
+		ProceedOrExit(true)
+	}
+	for _,e := range edited {
+		groups[e.StartDayString()].Add(e)
+	}
+	writeScheduleGroups(groups,days)
+}
+
 func prolongFinish(newtime string) {
 	day := ExpandPossibleEmptyToToday(newtime)
 	today := day
@@ -188,6 +422,8 @@ func prolongFinish(newtime string) {
 	ProceedOrExit(true)
 	ok := item.SetFinishString(newtime)
 	fatalFalsef(ok,"Invalid new finish time: %s",newtime)
+	item.AddNote(note)
+	setAttributes(item)
 	scheduleGroup.SetLast(item)
 	WriteFile(schedulePath,scheduleGroup.StringOfDay(day))
 	duration,_ := item.DurationString()
@@ -202,21 +438,53 @@ func list() {
 	startDay,toDay := evalDayPairByCommand("yesterday","today")
 	compilePatterns(settingGroups)
 	for _,day := range RangeDay(startDay,toDay) {
-		scheduleGroup := readScheduleGroupByDay(day)
+		scheduleGroup := readScheduleGroupInDay(day)
 		dayWithWeek,_ := schedule.GetDayWeekString(day)
 		fmt.Printf("Day %s\n",dayWithWeek)
 		for i := 0; i < scheduleGroup.Size(); i++ {
 			item,_ := scheduleGroup.Get(i)
+			if !matchTagFilter(item) || !matchSearch(item) || !matchWhereFilter(item) {
+				continue
+			}
 			group := getItemGroup(item.ContentString(),settingGroups)
 			if group != nil {
 				printColorSchemeHead(colorScheme,group.color)
 			}
 			item.Print()
+			if showNotes {
+				item.PrintNote()
+			}
 			if group != nil {
 				printColorSchemeTail(colorScheme,group.color)
 			}
 		}
 	}
+	if toDay == schedule.GetTodayString() {
+		listRunning()
+	}
+}
+
+func listRunning() {
+	slots := runningSlots()
+	if len(slots) == 0 {
+		return
+	}
+	fmt.Printf("Running\n")
+	for _,slot := range slots {
+		item := readStartItem(slot)
+		group := getItemGroup(item.ContentString(),settingGroups)
+		if group != nil {
+			printColorSchemeHead(colorScheme,group.color)
+		}
+		state := "since"
+		if item.Paused() {
+			state = "paused"
+		}
+		fmt.Printf("  %-10s %-6s %s: %s\n",slot,state,item.StartString(),item.ContentString())
+		if group != nil {
+			printColorSchemeTail(colorScheme,group.color)
+		}
+	}
 }
 
 func stat() {
@@ -224,23 +492,31 @@ func stat() {
 		statUsage()
 	}
 	startDay,toDay := getDayPairFromCommand()
-	oneDayBefore,_ := schedule.DayAddString(startDay,-1)
+	if statBy == "tag" {
+		statByTag(startDay,toDay)
+		return
+	} else if statBy != "group" {
+		statByAttribute(startDay,toDay,statBy)
+		return
+	}
 	totalMinutes,sum,startCount := 0,0,false
 	compilePatterns(settingGroups)
 	globalGroup := settingGroups["global"]
-	from,to,_ := schedule.GetRange(startDay,toDay)
-	for _,day := range RangeDay(oneDayBefore,toDay) {
-		scheduleGroup := readScheduleGroupByDay(day)
+	for _,day := range RangeDay(startDay,toDay) {
+		scheduleGroup := readScheduleGroupInDay(day)
 		for i := 0; i < scheduleGroup.Size(); i++ {
 			item,_ := scheduleGroup.Get(i)
-			duration,_ := item.DurationWithin(from,to)
+			if !matchTagFilter(item) {
+				continue
+			}
+			duration,_ := item.Duration()
 			group := getItemGroup(item.ContentString(),settingGroups)
 			if group != nil {
 				group.minute += duration
 				sum += duration
 			}
 		}
-		if !startCount && !scheduleGroup.Empty() && day != oneDayBefore {
+		if !startCount && !scheduleGroup.Empty() {
 			startCount = true
 			startDay = day
 		}
@@ -249,13 +525,35 @@ func stat() {
 		}
 	}
 	globalGroup.minute = totalMinutes - sum
+	rollUpMinutes(settingGroups)
+	if jsonOutput {
+		record := &StatRecord{startDay,toDay,[]*GroupTimeRecord{},totalMinutes}
+		for _,group := range treeSettingGroups(settingGroups) {
+			record.Groups = append(record.Groups,NewGroupTimeRecord(group,totalMinutes))
+		}
+		writeJSON(record)
+		return
+	}
 	fmt.Printf("Statistics from %s to %s:\n",startDay,toDay)
-	for _,group := range serializedSettingGroups(settingGroups) {
+	if !hasHierarchy(settingGroups) {
+		for _,group := range serializedSettingGroups(settingGroups) {
+			printColorSchemeHead(colorScheme,group.color)
+			group.printTimePercent(totalMinutes)
+			printColorSchemeTail(colorScheme,group.color)
+			printTargetProgress(group,group.total,totalMinutes/MINUTES_IN_A_DAY,12)
+		}
+		fmt.Printf("%12s: %5d hours %2d minutes\n","Total",totalMinutes/60,totalMinutes%60)
+		return
+	}
+	groups := treeSettingGroups(settingGroups)
+	labels,totalLabel := treeLabels(groups)
+	for _,group := range groups {
 		printColorSchemeHead(colorScheme,group.color)
-		group.printTimePercent(totalMinutes)
+		printTimePercent(labels[group],group.total,totalMinutes)
 		printColorSchemeTail(colorScheme,group.color)
+		printTargetProgress(group,group.total,totalMinutes/MINUTES_IN_A_DAY,len(totalLabel))
 	}
-	fmt.Printf("%12s: %5d hours %2d minutes\n","Total",totalMinutes/60,totalMinutes%60)
+	fmt.Printf("%s: %5d hours %2d minutes\n",totalLabel,totalMinutes/60,totalMinutes%60)
 }
 
 func plot() {
@@ -268,7 +566,7 @@ func plot() {
 	colorArray := make([]color.Color,totalMinutes)
 	compilePatterns(settingGroups)
 	for d,day := range dayRange {
-		scheduleGroup := readScheduleGroupByDay(day)
+		scheduleGroup := readScheduleGroupInDay(day)
 		for i := 0; i < scheduleGroup.Size(); i++ {
 			item,_ := scheduleGroup.Get(i)
 			content := item.ContentString()
@@ -291,7 +589,7 @@ func drawSchedule() {
 	colorArray := make([]color.Color,totalMinutes)
 	compilePatterns(settingGroups)
 	for d,day := range dayRange {
-		scheduleGroup := readScheduleGroupByDay(day)
+		scheduleGroup := readScheduleGroupInDay(day)
 		for i := 0; i < scheduleGroup.Size(); i++ {
 			item,_ := scheduleGroup.Get(i)
 			content := item.ContentString()
@@ -305,6 +603,38 @@ func drawSchedule() {
 	fmt.Printf("%s saved to current directory.\n","schedule.png")
 }
 
+func explain() {
+	if flag.NArg() < 2 || (flag.NArg() == 2 && flag.Arg(1) == "help") {
+		explainUsage()
+	}
+	content := strings.Join(flag.Args()[1:]," ")
+	compilePatterns(settingGroups)
+	groups := matchingGroups(content,settingGroups)
+	if len(groups) == 0 {
+		fmt.Printf("No group matches: %s\n",content)
+		fmt.Printf("Counted in: %s\n",settingGroups["global"].label)
+		return
+	}
+	fmt.Printf("Groups matching: %s\n",content)
+	for i,group := range groups {
+		mark := " "
+		if i == 0 {
+			mark = "*"
+		}
+		pattern,_ := group.matchedPattern(content)
+		printColorSchemeHead(colorScheme,group.color)
+		fmt.Printf("%s %s /%s/ priority %d\n",mark,schedule.PadRight(group.name,16),pattern,group.priority)
+		printColorSchemeTail(colorScheme,group.color)
+	}
+	for _,group := range orderedSettingGroups(settingGroups) {
+		pattern,exclude := group.matchedPattern(content)
+		if exclude != "" {
+			fmt.Printf("  %s /%s/ excluded by /%s/\n",schedule.PadRight(group.name,16),pattern,exclude)
+		}
+	}
+	fmt.Printf("Counted in: %s\n",groups[0].label)
+}
+
 func job() {
 	if flag.NArg() == 2 && flag.Arg(1) == "help" {
 		jobUsage()
@@ -314,9 +644,12 @@ func job() {
 	dayRange := RangeDay(startDay,toDay)
 	globalGroup := settingGroups["global"]
 	for _,day := range dayRange {
-		scheduleGroup := readScheduleGroupByDay(day)
+		scheduleGroup := readScheduleGroupInDay(day)
 		for i := 0; i < scheduleGroup.Size(); i++ {
 			item,_ := scheduleGroup.Get(i)
+			if !matchTagFilter(item) {
+				continue
+			}
 			content := item.ContentString()
 			group := getItemGroup(content,settingGroups)
 			if group == nil {
@@ -325,15 +658,27 @@ func job() {
 			group.Update(item)
 		}
 	}
+	if jsonOutput {
+		record := &JobsRecord{startDay,toDay,[]*GroupJobsRecord{},nil}
+		for _,group := range treeSettingGroups(settingGroups) {
+			groupRecord := &GroupJobsRecord{group.label,parentLabel(group),[]*JobRecord{}}
+			for _,job := range group.GetJobs() {
+				groupRecord.Jobs = append(groupRecord.Jobs,NewJobRecord("",job))
+			}
+			record.Groups = append(record.Groups,groupRecord)
+		}
+		writeJSON(record)
+		return
+	}
 	fmt.Printf("From %s to %s:\n",startDay,toDay)
-	for _,group := range serializedSettingGroups(settingGroups) {
+	for _,group := range treeSettingGroups(settingGroups) {
 		printColorSchemeHead(colorScheme,group.color)
-		fmt.Printf("[%s]\n",group.label)
+		fmt.Printf("%s[%s]\n",indent(group.depth),group.label)
 		printColorSchemeTail(colorScheme,group.color)
 		jobs := group.GetJobs()
 		for _,job := range jobs {
 			printColorSchemeHead(colorScheme,job.GetColor())
-			job.Print()
+			job.Print(group.depth)
 			printColorSchemeTail(colorScheme,job.GetColor())
 		}
 	}
@@ -348,25 +693,38 @@ func jobstat() {
 	dayRange := RangeDay(startDay,toDay)
 	jobset := NewJobSet()
 	for _,day := range dayRange {
-		scheduleGroup := readScheduleGroupByDay(day)
+		scheduleGroup := readScheduleGroupInDay(day)
 		for i := 0; i < scheduleGroup.Size(); i++ {
 			item,_ := scheduleGroup.Get(i)
+			if !matchTagFilter(item) {
+				continue
+			}
 			jobset.Update(item)
 		}
 	}
-	fmt.Printf("From %s to %s:\n",startDay,toDay)
 	jobs := jobset.GetJobsByTime()
 	globalGroup := settingGroups["global"]
+	record := &JobsRecord{startDay,toDay,nil,[]*JobRecord{}}
+	if !jsonOutput {
+		fmt.Printf("From %s to %s:\n",startDay,toDay)
+	}
 	for _,job := range jobs {
 		group := getItemGroup(job.Content(),settingGroups)
 		if group == nil {
 			group = globalGroup
 		}
+		if jsonOutput {
+			record.Jobs = append(record.Jobs,NewJobRecord(group.label,job))
+			continue
+		}
 		printColorSchemeHead(colorScheme,group.color)
-		fmt.Printf("%12s ",group.label)
-		job.Print()
+		fmt.Printf("%s ",schedule.PadLeft(group.label,12))
+		job.Print(0)
 		printColorSchemeTail(colorScheme,group.color)
 	}
+	if jsonOutput {
+		writeJSON(record)
+	}
 }
 
 func task() {
@@ -425,13 +783,17 @@ func main() {
 	setPath()
 
 	readConfig()
-	readSetting()
+	setZone()
 
 	if flag.NArg() < 1 {
 		usage()
 	}
 	command := flag.Arg(0)
 
+	if command != "check" {
+		readSetting()
+	}
+
 	if command == "help" {
 		usage()
 	} else if command == "set" {
@@ -444,6 +806,22 @@ func main() {
 		cancel()
 	} else if command == "finish" {
 		finish()
+	} else if command == "add" {
+		add()
+	} else if command == "edit" {
+		edit()
+	} else if command == "pause" {
+		pause()
+	} else if command == "resume" {
+		resume()
+	} else if command == "pomodoro" {
+		pomodoro()
+	} else if command == "note" {
+		noteCommand()
+	} else if command == "status" {
+		status()
+	} else if command == "search" {
+		searchHistory()
 	} else if command == "list" {
 		list()
 	} else if command == "stat" || command == "statistic" {
@@ -452,12 +830,24 @@ func main() {
 		plot()
 	} else if command == "draw" {
 		drawSchedule()
+	} else if command == "goals" {
+		goals()
+	} else if command == "explain" {
+		explain()
 	} else if command == "job" {
 		job()
 	} else if command == "jobstat" {
 		jobstat()
+	} else if command == "export" {
+		export()
+	} else if command == "import" {
+		importSchedule()
+	} else if command == "ics" {
+		ics()
 	} else if command == "task" {
 		task()
+	} else if command == "check" {
+		check()
 	} else {
 		usage()
 	}
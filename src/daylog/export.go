@@ -0,0 +1,148 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"flag"
+	"strconv"
+	"encoding/csv"
+	"encoding/json"
+	"schedule"
+)
+
+var format string
+var jsonOutput bool
+
+type ItemRecord struct {
+	Day string `json:"day"`
+	Start string `json:"start"`
+	Finish string `json:"finish"`
+	Duration int `json:"duration"`
+	Content string `json:"content"`
+	Group string `json:"group"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Note string `json:"note,omitempty"`
+}
+
+type GroupTimeRecord struct {
+	Group string `json:"group"`
+	Parent string `json:"parent,omitempty"`
+	Minutes int `json:"minutes"`
+	Percent float64 `json:"percent"`
+	Targets []*TargetRecord `json:"targets,omitempty"`
+}
+
+type StatRecord struct {
+	From string `json:"from"`
+	To string `json:"to"`
+	Groups []*GroupTimeRecord `json:"groups"`
+	Total int `json:"total"`
+}
+
+type JobRecord struct {
+	Group string `json:"group,omitempty"`
+	Content string `json:"content"`
+	Last string `json:"last"`
+	Since int `json:"since"`
+	Duration int `json:"duration"`
+}
+
+type GroupJobsRecord struct {
+	Group string `json:"group"`
+	Parent string `json:"parent,omitempty"`
+	Jobs []*JobRecord `json:"jobs"`
+}
+
+type JobsRecord struct {
+	From string `json:"from"`
+	To string `json:"to"`
+	Groups []*GroupJobsRecord `json:"groups,omitempty"`
+	Jobs []*JobRecord `json:"jobs,omitempty"`
+}
+
+func export() {
+	if flag.NArg() == 2 && flag.Arg(1) == "help" {
+		exportUsage()
+	}
+	startDay,toDay := getDayPairFromCommand()
+	compilePatterns(settingGroups)
+	items := []*ItemRecord{}
+	for _,day := range RangeDay(startDay,toDay) {
+		scheduleGroup := readScheduleGroupByDay(day)
+		for i := 0; i < scheduleGroup.Size(); i++ {
+			item,_ := scheduleGroup.Get(i)
+			items = append(items,NewItemRecord(day,item))
+		}
+	}
+	switch format {
+	case "json":
+		writeJSON(items)
+	case "csv":
+		writeCSV(items)
+	case "timeclock":
+		writeTimeclock(items)
+	case "timewarrior":
+		writeTimewarrior(items)
+	default:
+		fatalTruef(true,"Unsupported export format: %s",format)
+	}
+}
+
+func NewItemRecord(day string,item *schedule.ScheduleItem) *ItemRecord {
+	duration,_ := item.Duration()
+	record := &ItemRecord{day,item.StartString(),item.FinishString(),duration,item.ContentString(),"",item.Attributes(),item.Note()}
+	group := getItemGroup(item.ContentString(),settingGroups)
+	if group != nil {
+		record.Group = group.label
+	}
+	return record
+}
+
+// NewGroupTimeRecord records the time of a group including its subgroups.
+func NewGroupTimeRecord(g *SettingGroup,total int) *GroupTimeRecord {
+	record := NewTimeRecord(g.label,g.total,total)
+	record.Parent = parentLabel(g)
+	if len(g.targets()) > 0 {
+		record.Targets = NewTargetRecords(g,g.total,total/MINUTES_IN_A_DAY)
+	}
+	return record
+}
+
+func parentLabel(g *SettingGroup) string {
+	if parent,ok := settingGroups[g.parent]; ok {
+		return parent.label
+	}
+	return ""
+}
+
+func NewTimeRecord(name string,minute,total int) *GroupTimeRecord {
+	percent := 0.0
+	if total > 0 {
+		percent = float64(minute)/float64(total)*100
+	}
+	return &GroupTimeRecord{name,"",minute,percent,nil}
+}
+
+func NewJobRecord(group string,job *Job) *JobRecord {
+	return &JobRecord{group,job.content,job.last,job.Since(),job.duration}
+}
+
+var csvHeader = []string{"day","start","finish","minutes","content","group"}
+
+func writeCSV(items []*ItemRecord) {
+	writer := csv.NewWriter(os.Stdout)
+	writer.Write(csvHeader)
+	for _,item := range items {
+		writer.Write([]string{item.Day,item.Start,item.Finish,
+			strconv.Itoa(item.Duration),item.Content,item.Group})
+	}
+	writer.Flush()
+	fatalError("Error writing csv",writer.Error())
+}
+
+func writeJSON(v interface{}) {
+	data,err := json.MarshalIndent(v,"","  ")
+	fatalError("Error encoding json",err)
+	os.Stdout.Write(data)
+	fmt.Println()
+}
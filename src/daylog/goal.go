@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"flag"
+	"errors"
+	"regexp"
+	"strings"
+	"strconv"
+	"schedule"
+)
+
+const PROGRESS_WIDTH = 20
+
+var targetPattern = regexp.MustCompile("^(?:(min|max)\\s+)?(?:(\\d+):(\\d\\d)|(\\d+))$")
+
+// Target is the time a group should take in a day or a week, at least, or
+// with max, at most.
+type Target struct {
+	minute int
+	max bool
+}
+
+// parseTarget parses a target in minutes or in h:mm, like 90, 1:30 or
+// max 1:00.
+func parseTarget(s string) (*Target,error) {
+	groups := targetPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if groups == nil {
+		return nil,errors.New("invalid target: "+s)
+	}
+	minute := 0
+	if groups[4] != "" {
+		minute,_ = strconv.Atoi(groups[4])
+	} else {
+		hour,_ := strconv.Atoi(groups[2])
+		minute,_ = strconv.Atoi(groups[3])
+		if minute >= 60 {
+			return nil,errors.New("invalid target: "+s)
+		}
+		minute += hour*60
+	}
+	return &Target{minute,groups[1] == "max"},nil
+}
+
+func (t *Target) String() string {
+	if t.max {
+		return "max "+minuteString(t.minute)
+	}
+	return minuteString(t.minute)
+}
+
+func (t *Target) Kind() string {
+	if t.max {
+		return "at most"
+	}
+	return "at least"
+}
+
+// Met tells whether the minutes spent meet the target when it is scaled to
+// the given fraction, e.g. the number of days for a daily target.
+func (t *Target) Met(minute int,scale float64) bool {
+	if t.max {
+		return float64(minute) <= float64(t.minute)*scale
+	}
+	return float64(minute) >= float64(t.minute)*scale
+}
+
+func minuteString(minute int) string {
+	if minute < 0 {
+		return "-"+minuteString(-minute)
+	}
+	return fmt.Sprintf("%d:%02d",minute/60,minute%60)
+}
+
+type groupTarget struct {
+	period string
+	target *Target
+	days int
+}
+
+// targets lists the targets of the group with the number of days a period
+// spans.
+func (g *SettingGroup) targets() []*groupTarget {
+	targets := []*groupTarget{}
+	if g.dailyTarget != nil {
+		targets = append(targets,&groupTarget{"daily",g.dailyTarget,1})
+	}
+	if g.weeklyTarget != nil {
+		targets = append(targets,&groupTarget{"weekly",g.weeklyTarget,7})
+	}
+	return targets
+}
+
+// progressString shows the progress of the minutes towards a target scaled
+// to the given number of days, with the amount over or under it.
+func (t *groupTarget) progressString(minute,days int) string {
+	scale := float64(days)/float64(t.days)
+	goal := int(float64(t.target.minute)*scale+0.5)
+	filled,percent := PROGRESS_WIDTH,0
+	if goal > 0 {
+		percent = minute*100/goal
+		if minute < goal {
+			filled = minute*PROGRESS_WIDTH/goal
+		}
+	}
+	bar := strings.Repeat("#",filled)+strings.Repeat("-",PROGRESS_WIDTH-filled)
+	status := "hit"
+	if !t.target.Met(minute,scale) {
+		status = "miss"
+	}
+	difference := "on target"
+	if minute > goal {
+		difference = minuteString(minute-goal)+" over"
+	} else if minute < goal {
+		difference = minuteString(goal-minute)+" under"
+	}
+	return fmt.Sprintf("%-6s %s %s [%s] %3d%% %s, %s",
+		t.period,t.target.Kind(),minuteString(goal),bar,percent,difference,status)
+}
+
+// printTargetProgress prints the progress of a group towards its targets
+// over the given number of days, under the line of the group.
+func printTargetProgress(g *SettingGroup,minute,days,width int) {
+	for _,target := range g.targets() {
+		fmt.Printf("%*s  %s\n",width,"",target.progressString(minute,days))
+	}
+}
+
+type TargetRecord struct {
+	Period string `json:"period"`
+	Max bool `json:"max"`
+	Target int `json:"target"`
+	Met bool `json:"met"`
+}
+
+func NewTargetRecords(g *SettingGroup,minute,days int) []*TargetRecord {
+	records := []*TargetRecord{}
+	for _,target := range g.targets() {
+		scale := float64(days)/float64(target.days)
+		records = append(records,&TargetRecord{target.period,target.target.max,
+			int(float64(target.target.minute)*scale+0.5),target.target.Met(minute,scale)})
+	}
+	return records
+}
+
+// dayGroupMinutes sums up the minutes of the groups in a day, rolled up to
+// the parent groups.
+func dayGroupMinutes(day string,minutes map[*SettingGroup]int) {
+	scheduleGroup := readScheduleGroupInDay(day)
+	for i := 0; i < scheduleGroup.Size(); i++ {
+		item,_ := scheduleGroup.Get(i)
+		duration,_ := item.Duration()
+		for g := getItemGroup(item.ContentString(),settingGroups); g != nil; g = settingGroups[g.parent] {
+			minutes[g] += duration
+		}
+	}
+}
+
+type goalCount struct {
+	hit int
+	miss int
+}
+
+func (count *goalCount) Add(met bool) {
+	if met {
+		count.hit += 1
+	} else {
+		count.miss += 1
+	}
+}
+
+func goals() {
+	if flag.NArg() == 2 && flag.Arg(1) == "help" {
+		goalsUsage()
+	}
+	startDay,toDay := getDayPairFromCommand()
+	compilePatterns(settingGroups)
+	groups := []*SettingGroup{}
+	for _,group := range treeSettingGroups(settingGroups) {
+		if len(group.targets()) > 0 {
+			groups = append(groups,group)
+		}
+	}
+	if len(groups) == 0 {
+		fmt.Printf("No targets set. Set one with: daylog set group.daily_target=h:mm\n")
+		return
+	}
+	daily,weekly := make(map[*SettingGroup]*goalCount),make(map[*SettingGroup]*goalCount)
+	for _,group := range groups {
+		daily[group],weekly[group] = &goalCount{},&goalCount{}
+	}
+	fmt.Printf("Goals from %s to %s:\n",startDay,toDay)
+	week,weekDays := make(map[*SettingGroup]int),0
+	days := RangeDay(startDay,toDay)
+	for i,day := range days {
+		minutes := make(map[*SettingGroup]int)
+		dayGroupMinutes(day,minutes)
+		weekString,_ := schedule.GetDayWeekString(day)
+		fmt.Printf("%s\n",weekString)
+		for _,group := range groups {
+			week[group] += minutes[group]
+			if group.dailyTarget == nil {
+				continue
+			}
+			met := group.dailyTarget.Met(minutes[group],1)
+			daily[group].Add(met)
+			printGoal(group,minutes[group],group.dailyTarget.minute,group.dailyTarget,met)
+		}
+		weekDays += 1
+		if i < len(days)-1 && !lastDayOfWeek(day) {
+			continue
+		}
+		fmt.Printf("Week to %s\n",weekString)
+		for _,group := range groups {
+			if group.weeklyTarget != nil {
+				scale := float64(weekDays)/7
+				met := group.weeklyTarget.Met(week[group],scale)
+				weekly[group].Add(met)
+				printGoal(group,week[group],int(float64(group.weeklyTarget.minute)*scale+0.5),group.weeklyTarget,met)
+			}
+		}
+		week,weekDays = make(map[*SettingGroup]int),0
+	}
+	fmt.Printf("Summary:\n")
+	for _,group := range groups {
+		fmt.Printf("  %s",schedule.PadRight(group.label,12))
+		if group.dailyTarget != nil {
+			fmt.Printf(" daily %d hit %d missed",daily[group].hit,daily[group].miss)
+		}
+		if group.weeklyTarget != nil {
+			fmt.Printf(" weekly %d hit %d missed",weekly[group].hit,weekly[group].miss)
+		}
+		fmt.Println()
+	}
+}
+
+// lastDayOfWeek tells whether the day ends a week starting on week_start.
+func lastDayOfWeek(day string) bool {
+	return calendarDay(day).AddDate(0,0,1).Weekday() == weekStartFromConfiguration()
+}
+
+func printGoal(g *SettingGroup,minute,goal int,target *Target,met bool) {
+	status,color := "hit ","green"
+	if !met {
+		status,color = "miss","red"
+	}
+	printColorSchemeHead(colorScheme,color)
+	fmt.Printf("  %s %s %6s of %s %s\n",status,schedule.PadRight(g.label,12),minuteString(minute),target.Kind(),minuteString(goal))
+	printColorSchemeTail(colorScheme,color)
+}
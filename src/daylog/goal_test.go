@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		s string
+		minute int
+		max bool
+		ok bool
+	}{
+		{"90",90,false,true},
+		{"1:30",90,false,true},
+		{"0:05",5,false,true},
+		{"max 1:00",60,true,true},
+		{"min 2:15",135,false,true},
+		{" max 45 ",45,true,true},
+		{"1:60",0,false,false},
+		{"1:5",0,false,false},
+		{"most 1:00",0,false,false},
+		{"",0,false,false},
+	}
+	for _,test := range tests {
+		target,err := parseTarget(test.s)
+		if (err == nil) != test.ok {
+			t.Errorf("parseTarget(%q) failed! Expect ok %v, got error %v\n",test.s,test.ok,err)
+			continue
+		}
+		if err == nil && (target.minute != test.minute || target.max != test.max) {
+			t.Errorf("parseTarget(%q) failed! Expect %d %v, got %d %v\n",
+				test.s,test.minute,test.max,target.minute,target.max)
+		}
+	}
+}
+
+func TestTargetMet(t *testing.T) {
+	target,_ := parseTarget("1:00")
+	if !target.Met(60,1) || target.Met(59,1) || !target.Met(30,0.5) {
+		t.Errorf("Target.Met() failed for at least 1:00\n")
+	}
+	limit,_ := parseTarget("max 1:00")
+	if !limit.Met(60,1) || limit.Met(61,1) || limit.Met(31,0.5) {
+		t.Errorf("Target.Met() failed for at most 1:00\n")
+	}
+	if target.String() != "1:00" || limit.String() != "max 1:00" {
+		t.Errorf("Target.String() failed! Got %s and %s\n",target,limit)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"flag"
+	"time"
+	"strings"
+	"schedule"
+	"crypto/sha1"
+)
+
+const (
+	ICS_FORMAT = "20060102T150405"
+	ICS_LINE_LENGTH = 75
+)
+
+var icsEscaper = strings.NewReplacer("\\","\\\\",";","\\;",",","\\,","\n","\\n")
+
+func ics() {
+	if flag.NArg() == 2 && flag.Arg(1) == "help" {
+		icsUsage()
+	}
+	startDay,toDay := getDayPairFromCommand()
+	compilePatterns(settingGroups)
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//daylog//daylog//EN",
+		"CALSCALE:GREGORIAN",
+	}
+	for _,day := range RangeDay(startDay,toDay) {
+		scheduleGroup := readScheduleGroupByDay(day)
+		for i := 0; i < scheduleGroup.Size(); i++ {
+			item,_ := scheduleGroup.Get(i)
+			lines = append(lines,icsEvent(item)...)
+		}
+	}
+	lines = append(lines,"END:VCALENDAR")
+	for _,line := range lines {
+		fmt.Printf("%s\r\n",foldICSLine(line))
+	}
+}
+
+func icsEvent(item *schedule.ScheduleItem) []string {
+	uid := fmt.Sprintf("%x@daylog",sha1.Sum([]byte(item.String())))
+	lines := []string{
+		"BEGIN:VEVENT",
+		"UID:"+uid,
+		"DTSTAMP:"+schedule.Absolute(*item.Finish()).UTC().Format(ICS_FORMAT)+"Z",
+		"DTSTART:"+icsTime(*item.Start()),
+		"DTEND:"+icsTime(*item.Finish()),
+		"SUMMARY:"+icsEscaper.Replace(item.ContentString()),
+	}
+	group := getItemGroup(item.ContentString(),settingGroups)
+	if group != nil {
+		lines = append(lines,"CATEGORIES:"+icsEscaper.Replace(group.label))
+	}
+	return append(lines,"END:VEVENT")
+}
+
+// icsTime writes a time in UTC, or as a floating local time when no zone is
+// configured and the times in the day files are plain wall clock times.
+func icsTime(t time.Time) string {
+	if !schedule.ZoneEnabled() {
+		return t.Format(ICS_FORMAT)
+	}
+	return t.UTC().Format(ICS_FORMAT)+"Z"
+}
+
+// foldICSLine splits lines longer than 75 octets, continuing them with a
+// leading space, without breaking a UTF-8 character.
+func foldICSLine(line string) string {
+	folded := ""
+	limit := ICS_LINE_LENGTH
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		folded += line[:cut]+"\r\n "
+		line = line[cut:]
+		limit = ICS_LINE_LENGTH-1
+	}
+	return folded+line
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"os"
+	"io"
+	"fmt"
+	"flag"
+	"sort"
+	"strings"
+	"schedule"
+	"path/filepath"
+	"encoding/csv"
+)
+
+func importSchedule() {
+	if flag.NArg() != 2 || flag.Arg(1) == "help" {
+		importUsage()
+	}
+	compilePatterns(settingGroups)
+	items := []*schedule.ScheduleItem{}
+	var open *schedule.ScheduleItem
+	for _,filename := range importFiles(flag.Arg(1)) {
+		file,err := os.Open(filename)
+		fatalError("Error opening "+filename,err)
+		var fileItems []*schedule.ScheduleItem
+		var fileOpen *schedule.ScheduleItem
+		switch format {
+		case "csv":
+			fileItems = readCSVItems(file)
+		case "timeclock":
+			fileItems,fileOpen = readTimeclockItems(file)
+		case "timewarrior":
+			fileItems,fileOpen = readTimewarriorItems(file)
+		default:
+			fatalTruef(true,"Unsupported import format: %s",format)
+		}
+		file.Close()
+		items = append(items,fileItems...)
+		if fileOpen != nil {
+			open = fileOpen
+		}
+	}
+	importItems(items)
+	if open != nil {
+		startImportedItem(open)
+	}
+}
+
+// importFiles lists the files to import. A directory stands for its data
+// files, such as the monthly files of timewarrior.
+func importFiles(name string) []string {
+	info,err := os.Stat(name)
+	fatalError("Error opening "+name,err)
+	if !info.IsDir() {
+		return []string{name}
+	}
+	extension := format
+	if format == "timewarrior" {
+		extension = "data"
+	}
+	files,err := filepath.Glob(filepath.Join(name,"*."+extension))
+	fatalError("Error listing "+name,err)
+	sort.Strings(files)
+	return files
+}
+
+// readCSVItems reads items in the columns written by export. The columns
+// are found by the header if there is one.
+func readCSVItems(r io.Reader) []*schedule.ScheduleItem {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows,err := reader.ReadAll()
+	fatalError("Error reading csv",err)
+	columns := map[string]int{"start":1,"finish":2,"content":4}
+	if len(rows) > 0 && rows[0][0] == csvHeader[0] {
+		for i,name := range rows[0] {
+			columns[name] = i
+		}
+		rows = rows[1:]
+	}
+	items := []*schedule.ScheduleItem{}
+	for i,row := range rows {
+		field := func(name string) string {
+			if columns[name] >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[columns[name]])
+		}
+		start,ok1 := schedule.GetFullTime(field("start"))
+		finish,ok2 := schedule.GetFullTime(field("finish"))
+		fatalFalsef(ok1 && ok2,"Invalid time in csv row %d",i+1)
+		items = append(items,newImportedItem(start,finish,field("content"),i+1))
+	}
+	return items
+}
+
+// contentInGroup tells whether the patterns already put the content in the
+// group with the name or label, so that an imported account or tag naming
+// the group adds nothing to the content.
+func contentInGroup(content,name string) bool {
+	group := getItemGroup(content,settingGroups)
+	if group == nil {
+		group = settingGroups["global"]
+	}
+	return group != nil && (group.name == name || group.label == name)
+}
+
+func newImportedItem(start,finish,content string,row int) *schedule.ScheduleItem {
+	item := schedule.NewScheduleItem()
+	fatalFalsef(item.SetStartFinishString(start,finish),"Invalid time range in row %d: %s to %s",row,start,finish)
+	item.SetContent(strings.Join(strings.Fields(content)," "))
+	_,err := schedule.ScheduleItemFromString(item.String())
+	fatalErrorf(err,"Invalid item in row %d",row)
+	return item
+}
+
+// importItems merges the items into the day files, skipping the items that
+// are already there and asking before adding the overlapping ones.
+func importItems(items []*schedule.ScheduleItem) {
+	if len(items) == 0 {
+		fmt.Printf("Nothing to import.\n")
+		return
+	}
+	sort.SliceStable(items,func (i,j int) bool {
+		return items[i].Start().Before(*items[j].Start())
+	})
+	firstDay,_ := schedule.DayAddString(items[0].StartDayString(),-1)
+	lastDay := items[len(items)-1].StartDayString()
+	existing := []*schedule.ScheduleItem{}
+	for _,day := range RangeDay(firstDay,lastDay) {
+		scheduleGroup := readScheduleGroupByDay(day)
+		for i := 0; i < scheduleGroup.Size(); i++ {
+			item,_ := scheduleGroup.Get(i)
+			existing = append(existing,item)
+		}
+	}
+	fresh,overlapping,duplicated := []*schedule.ScheduleItem{},[]*schedule.ScheduleItem{},0
+	for _,item := range items {
+		duplicate,overlap := false,false
+		for _,e := range existing {
+			if e.String() == item.String() {
+				duplicate = true
+				break
+			}
+			overlap = overlap || e.Overlaps(item)
+		}
+		if duplicate {
+			duplicated += 1
+			continue
+		}
+		if overlap {
+			overlapping = append(overlapping,item)
+		} else {
+			fresh = append(fresh,item)
+		}
+		existing = append(existing,item)
+	}
+	fmt.Printf("%d new, %d duplicated, %d overlapping items\n",len(fresh),duplicated,len(overlapping))
+	if len(overlapping) > 0 {
+		fmt.Printf("Overlapping items:\n")
+		for _,item := range overlapping {
+			item.Print()
+		}
+		fmt.Printf("Import overlapping items too? (y/N)")
+		if UserProceed(false) {
+			fresh = append(fresh,overlapping...)
+		}
+	}
+	if len(fresh) == 0 {
+		return
+	}
+	fmt.Printf("Going to import %d items. Proceed? (Y/n)",len(fresh))
+	ProceedOrExit(true)
+	addScheduleItems(fresh)
+	fmt.Printf("Imported %d items.\n",len(fresh))
+}
@@ -93,11 +93,13 @@ func (job *Job) GetColor() string {
 	}
 }
 
-func (job *Job) Print() {
+// Print prints the job indented to the depth of its group, with the last
+// time lined up across depths.
+func (job *Job) Print(depth int) {
 	weekString,ok := schedule.GetDayWeekString(job.last)
 	fatalFalsef(ok,"Invalid last time: %s",job.last)
-	fmt.Printf("  %-32s last time %s (%s ago), time spent %s\n",
-		job.content,weekString,job.SinceString(),job.DurationString())
+	fmt.Printf("  %s%s last time %s (%s ago), time spent %s\n",indent(depth),
+		schedule.PadRight(job.content,32-len(indent(depth))),weekString,job.SinceString(),job.DurationString())
 }
 
 func (jobset *JobSet) Update(item *schedule.ScheduleItem) {
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"flag"
+	"regexp"
+	"strings"
+	"schedule"
+)
+
+var note string
+var showNotes bool
+var search string
+var searchCompiled *regexp.Regexp
+
+// note shows the note of the running job, or adds a line to it.
+func noteCommand() {
+	if flag.NArg() == 2 && flag.Arg(1) == "help" {
+		noteUsage()
+	}
+	item := readRunningItem()
+	printSlot()
+	if flag.NArg() == 1 {
+		fmt.Printf("Job: %s\n",item.ContentString())
+		item.PrintNote()
+		return
+	}
+	item.AddNote(strings.Join(flag.Args()[1:]," "))
+	WriteFile(startPath,item.RunningString())
+	fmt.Printf("Note of %s:\n",item.ContentString())
+	item.PrintNote()
+}
+
+// matchSearch tells whether the content or the note of the item matches
+// the --search pattern, if there is one.
+func matchSearch(item *schedule.ScheduleItem) bool {
+	if search == "" {
+		return true
+	}
+	if searchCompiled == nil {
+		var err error
+		searchCompiled,err = regexp.Compile(search)
+		fatalErrorf(err,"Invalid search pattern: %s",search)
+	}
+	return searchCompiled.MatchString(item.ContentString()) || searchCompiled.MatchString(item.Note())
+}
@@ -12,10 +12,11 @@ var commentPattern *regexp.Regexp = nil
 var labelPattern *regexp.Regexp = nil
 var groupPattern *regexp.Regexp = nil
 var taskPattern *regexp.Regexp = nil
+var wordPattern *regexp.Regexp = nil
 
 func parseKeyValue(s string) (key,value string) {
 	if keyvaluePattern == nil {
-		pattern,err := regexp.Compile("^(\\w+)(=(\\w+))?$")
+		pattern,err := regexp.Compile("^(\\w+)(=(\\S+))?$")
 		fatalError("Error in parsing key=value regular expression",err)
 		keyvaluePattern = pattern
 	}
@@ -31,28 +32,31 @@ func parseKeyValue(s string) (key,value string) {
 	return
 }
 
-func parseGroupKeyValue(s string) (group,key,value string) {
+// parseGroupKeyValue parses group.key, group.key=value, and for the keys
+// holding several values, group.key+=value and group.key-=value.
+func parseGroupKeyValue(s string) (group,key,op,value string) {
 	if groupPattern == nil {
-		pattern,err := regexp.Compile("^(\\w+)\\.(\\w+)(=([ -~]+))?$")
+		pattern,err := regexp.Compile("^([\\p{L}\\p{N}_.]+)\\.(\\w+)(([+-]?)=([^\\p{Cc}]+))?$")
 		fatalError("Error in parsing group key=value regular expression",err)
 		groupPattern = pattern
 	}
 	if !groupPattern.MatchString(s) {
-		return "","",""
+		return "","","",""
 	}
 	pair := groupPattern.FindStringSubmatch(s)
-	if len(pair) != 5 {
-		return "","",""
+	if len(pair) != 6 {
+		return "","","",""
 	}
 	group = pair[1]
 	key = pair[2]
-	value = pair[4]
+	op = pair[4]
+	value = pair[5]
 	return
 }
 
 func parseSpecialKeyValue(s string) (key,value string) {
 	if specialPattern == nil {
-		pattern,err := regexp.Compile("^(\\w+)(=([ -~]*))?$")
+		pattern,err := regexp.Compile("^(\\w+)(=([^\\p{Cc}]*))?$")
 		fatalError("Error in parsing special key=value regular expression",err)
 		specialPattern = pattern
 	}
@@ -70,7 +74,7 @@ func parseSpecialKeyValue(s string) (key,value string) {
 
 func parseGroupLabel(s string) (label string) {
 	if labelPattern == nil {
-		pattern,err := regexp.Compile("^\\[(\\w+)\\]$")
+		pattern,err := regexp.Compile("^\\[([\\p{L}\\p{N}_]+(?:\\.[\\p{L}\\p{N}_]+)*)\\]$")
 		fatalError("Error in parsing special label regular expression",err)
 		labelPattern = pattern
 	}
@@ -102,9 +106,18 @@ func parseComment(s string) (ret string) {
 	return
 }
 
+func isWord(s string) bool {
+	if wordPattern == nil {
+		pattern,err := regexp.Compile("^\\w+$")
+		fatalError("Error in parsing word regular expression",err)
+		wordPattern = pattern
+	}
+	return wordPattern.MatchString(s)
+}
+
 func parseTask(s string) (name,content string,level int) {
 	if taskPattern == nil {
-		pattern,err := regexp.Compile("^\\s*(\\w+)\\s*,\\s*(\\d+)\\s*,\\s*([ -~]*)\\s*$")
+		pattern,err := regexp.Compile("^\\s*([\\p{L}\\p{N}_]+)\\s*,\\s*(\\d+)\\s*,\\s*([^\\p{Cc}]*?)\\s*$")
 		fatalError("Error in parsing task regular expression",err)
 		taskPattern = pattern
 	}
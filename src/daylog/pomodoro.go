@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"flag"
+	"time"
+	"syscall"
+	"schedule"
+	"os/signal"
+)
+
+const (
+	DEFAULT_POMODORO_WORK = 25*time.Minute
+	DEFAULT_POMODORO_BREAK = 5*time.Minute
+	DEFAULT_POMODORO_CYCLES = 4
+	DEFAULT_POMODORO_BREAK_CONTENT = "Break"
+)
+
+var pomodoroWork time.Duration
+var pomodoroBreak time.Duration
+var pomodoroCycles int
+
+// pomodoro alternates work intervals and breaks in the foreground. Every
+// interval is filed as a finished job, the breaks with the content set by
+// pomodoro_break in the configuration.
+func pomodoro() {
+	if flag.NArg() != 2 || flag.Arg(1) == "help" {
+		pomodoroUsage()
+	}
+	fatalTruef(pomodoroWork < time.Minute,"Work interval shorter than a minute: %s",pomodoroWork)
+	fatalTruef(pomodoroBreak < 0,"Invalid break: %s",pomodoroBreak)
+	fatalTruef(pomodoroCycles < 1,"Invalid number of cycles: %d",pomodoroCycles)
+	_,err := os.Stat(startPath)
+	fatalNotFileNotExistError(err)
+	if err == nil {
+		fatalTruef(true,"Task already started: %s",readStartItem(slot).ContentString())
+	}
+	readTasks()
+	content := getJobFromTask(flag.Arg(1))
+	breakContent := configuration["pomodoro_break"]
+	if breakContent == "" {
+		breakContent = DEFAULT_POMODORO_BREAK_CONTENT
+	}
+	interrupt := make(chan os.Signal,1)
+	signal.Notify(interrupt,os.Interrupt,syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	printSlot()
+	for cycle := 1; cycle <= pomodoroCycles; cycle++ {
+		fmt.Printf("Pomodoro %d/%d: %s\n",cycle,pomodoroCycles,content)
+		if !pomodoroInterval(content,pomodoroWork,interrupt) {
+			return
+		}
+		if cycle == pomodoroCycles || pomodoroBreak < time.Minute {
+			continue
+		}
+		fmt.Printf("Break %d/%d: %s\n",cycle,pomodoroCycles-1,breakContent)
+		if !pomodoroInterval(breakContent,pomodoroBreak,interrupt) {
+			return
+		}
+	}
+	fmt.Printf("Pomodoro done.\n")
+}
+
+// pomodoroInterval runs a job for the given time, or until it is
+// interrupted, then finishes it and rings the bell. It tells whether the
+// interval ran to its end.
+func pomodoroInterval(content string,d time.Duration,interrupt chan os.Signal) bool {
+	item := schedule.NewScheduleItem()
+	fatalFalse(item.SetStartString(schedule.GetNowString()),"Failed to set start time")
+	item.SetContent(content)
+	WriteFile(startPath,item.RunningString())
+	fmt.Printf("Started at time: %s, until %s\n",item.StartString(),
+		time.Now().Add(d).Format(schedule.FORMAT_CLOCK))
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	done := true
+	select {
+	case <-timer.C:
+	case <-interrupt:
+		fmt.Printf("\nInterrupted.\n")
+		done = false
+	}
+	finishTime := schedule.GetNowString()
+	if schedule.CompareTimeString(item.StartString(),finishTime) < 0 {
+		finishItem(item,finishTime)
+	} else {
+		err := os.Remove(startPath)
+		fatalError("Error removing starting file",err)
+		fmt.Printf("Shorter than a minute, not recorded.\n")
+	}
+	if done {
+		fmt.Printf("\a")
+	}
+	return done
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"flag"
+	"regexp"
+	"schedule"
+)
+
+var fixedSearch bool
+
+// searchHistory looks for a pattern in the content and the notes of the
+// jobs in every day file, and sums up when and how long they were done.
+func searchHistory() {
+	if flag.NArg() != 2 || flag.Arg(1) == "help" {
+		searchUsage()
+	}
+	search = flag.Arg(1)
+	if fixedSearch {
+		search = regexp.QuoteMeta(search)
+	}
+	compilePatterns(settingGroups)
+	count,total := 0,0
+	first,last := "",""
+	for _,day := range dayFiles() {
+		scheduleGroup := readScheduleGroupByDay(day)
+		header := false
+		for i := 0; i < scheduleGroup.Size(); i++ {
+			item,_ := scheduleGroup.Get(i)
+			if !matchSearch(item) {
+				continue
+			}
+			if !header {
+				dayWithWeek,_ := schedule.GetDayWeekString(day)
+				fmt.Printf("Day %s\n",dayWithWeek)
+				header = true
+			}
+			group := getItemGroup(item.ContentString(),settingGroups)
+			if group != nil {
+				printColorSchemeHead(colorScheme,group.color)
+			}
+			item.Print()
+			if showNotes {
+				item.PrintNote()
+			}
+			if group != nil {
+				printColorSchemeTail(colorScheme,group.color)
+			}
+			duration,_ := item.Duration()
+			count += 1
+			total += duration
+			if first == "" {
+				first = item.StartString()
+			}
+			last = item.FinishString()
+		}
+	}
+	if count == 0 {
+		fmt.Printf("No job matches: %s\n",flag.Arg(1))
+		return
+	}
+	fmt.Printf("Matches: %d\n",count)
+	fmt.Printf("First seen: %s\n",first)
+	fmt.Printf("Last seen: %s\n",last)
+	fmt.Printf("Total time: %d hours %d minutes\n",total/60,total%60)
+}
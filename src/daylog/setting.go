@@ -1,10 +1,11 @@
 package main
 
 import (
-	"log"
 	"fmt"
 	"sort"
 	"regexp"
+	"strconv"
+	"strings"
 	"schedule"
 )
 
@@ -12,9 +13,18 @@ type SettingGroup struct {
 	name string
 	label string
 	color string
-	pattern string
+	patterns []string
+	excludes []string
+	parent string
+	priority int
+	order int
+	dailyTarget *Target
+	weeklyTarget *Target
 	minute int
-	compiled *regexp.Regexp
+	total int
+	depth int
+	compiled []*regexp.Regexp
+	excluded []*regexp.Regexp
 	jobset *JobSet
 	taskset *TaskSet
 }
@@ -22,7 +32,9 @@ type SettingGroup struct {
 var settingGroups map[string]*SettingGroup
 
 func NewSettingGroup(name string) (g *SettingGroup) {
-	g = &SettingGroup{name,name,"","",0,nil,nil,nil}
+	parent := impliedParent(name)
+	label := strings.TrimPrefix(name[len(parent):],".")
+	g = &SettingGroup{name,label,"",nil,nil,parent,0,0,nil,nil,0,0,0,nil,nil,nil,nil}
 	g.jobset = NewJobSet()
 	g.taskset = NewTaskSet()
 	return
@@ -32,9 +44,25 @@ func (g *SettingGroup) set(key,value string) bool {
 	if key == "color" {
 		g.color = value
 	} else if key == "pattern" {
-		g.pattern = value
+		g.patterns = appendPattern(g.patterns,value)
+	} else if key == "exclude" {
+		g.excludes = appendPattern(g.excludes,value)
 	} else if key == "label" {
 		g.label = value
+	} else if key == "parent" {
+		g.parent = value
+	} else if key == "priority" {
+		priority,err := strconv.Atoi(value)
+		fatalErrorf(err,"Invalid priority of group %s: %s",g.name,value)
+		g.priority = priority
+	} else if key == "daily_target" || key == "weekly_target" {
+		target,err := parseTarget(value)
+		fatalErrorf(err,"Invalid %s of group %s",key,g.name)
+		if key == "daily_target" {
+			g.dailyTarget = target
+		} else {
+			g.weeklyTarget = target
+		}
 	}
 	return false
 }
@@ -43,43 +71,175 @@ func (g *SettingGroup) get(key string) (v string,ok bool) {
 	if key == "color" {
 		return g.color,true
 	} else if key == "pattern" {
-		return g.pattern,true
+		return strings.Join(g.patterns,"\n"),true
+	} else if key == "exclude" {
+		return strings.Join(g.excludes,"\n"),true
 	} else if key == "label" {
 		return g.label,true
+	} else if key == "parent" {
+		return g.parent,true
+	} else if key == "priority" {
+		return strconv.Itoa(g.priority),true
+	} else if key == "daily_target" {
+		return targetString(g.dailyTarget),true
+	} else if key == "weekly_target" {
+		return targetString(g.weeklyTarget),true
 	}
 	return "",false
 }
 
+// reset clears the patterns or the exclude patterns before they are set anew.
+func (g *SettingGroup) reset(key string) {
+	if key == "pattern" {
+		g.patterns = nil
+	} else if key == "exclude" {
+		g.excludes = nil
+	}
+}
+
+// remove takes a pattern or an exclude pattern away from the group.
+func (g *SettingGroup) remove(key,value string) bool {
+	if key == "pattern" {
+		patterns,ok := removePattern(g.patterns,value)
+		g.patterns = patterns
+		return ok
+	} else if key == "exclude" {
+		excludes,ok := removePattern(g.excludes,value)
+		g.excludes = excludes
+		return ok
+	}
+	return false
+}
+
+func appendPattern(patterns []string,pattern string) []string {
+	if pattern == "" {
+		return patterns
+	}
+	for _,p := range patterns {
+		if p == pattern {
+			return patterns
+		}
+	}
+	return append(patterns,pattern)
+}
+
+func removePattern(patterns []string,pattern string) ([]string,bool) {
+	for i,p := range patterns {
+		if p == pattern {
+			return append(patterns[:i:i],patterns[i+1:]...),true
+		}
+	}
+	return patterns,false
+}
+
 func (g *SettingGroup) String() string {
-	return fmt.Sprintf("[%s]\nlabel=%s\ncolor=%s\npattern=%s\n",g.name,g.label,g.color,g.pattern)
+	s := fmt.Sprintf("[%s]\nlabel=%s\ncolor=%s\n",g.name,g.label,g.color)
+	if len(g.patterns) == 0 {
+		s += "pattern=\n"
+	}
+	for _,pattern := range g.patterns {
+		s += fmt.Sprintf("pattern=%s\n",pattern)
+	}
+	for _,exclude := range g.excludes {
+		s += fmt.Sprintf("exclude=%s\n",exclude)
+	}
+	if g.parent != impliedParent(g.name) {
+		s += fmt.Sprintf("parent=%s\n",g.parent)
+	}
+	if g.priority != 0 {
+		s += fmt.Sprintf("priority=%d\n",g.priority)
+	}
+	if g.dailyTarget != nil {
+		s += fmt.Sprintf("daily_target=%s\n",g.dailyTarget)
+	}
+	if g.weeklyTarget != nil {
+		s += fmt.Sprintf("weekly_target=%s\n",g.weeklyTarget)
+	}
+	return s
+}
+
+func targetString(t *Target) string {
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
+
+// impliedParent is the parent given by a dotted name, e.g. work for
+// work.coding.
+func impliedParent(name string) string {
+	i := strings.LastIndex(name,".")
+	if i < 0 {
+		return ""
+	}
+	return name[:i]
 }
 
 func (g *SettingGroup) compilePattern() {
-	var err error
-	g.compiled,err = regexp.Compile(g.pattern)
-	if err != nil {
-		log.Fatalf("Failed to compile pattern for group %s: /%s/: %s\n",g.name,g.pattern,err.Error())
+	g.compiled = compileGroupPatterns(g.name,g.patterns)
+	g.excluded = compileGroupPatterns(g.name,g.excludes)
+}
+
+func compileGroupPatterns(name string,patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp,len(patterns))
+	for i,pattern := range patterns {
+		var err error
+		compiled[i],err = regexp.Compile(pattern)
+		if err != nil {
+			logFatalf("Failed to compile pattern for group %s: /%s/: %s\n",name,pattern,err.Error())
+		}
+	}
+	return compiled
+}
+
+// matchedPattern finds the pattern that puts the content in the group, and
+// the exclude pattern that keeps it out anyway.
+func (g *SettingGroup) matchedPattern(content string) (pattern,exclude string) {
+	for i,compiled := range g.compiled {
+		if compiled.MatchString(content) {
+			pattern = g.patterns[i]
+			break
+		}
+	}
+	if pattern == "" {
+		return "",""
 	}
+	for i,compiled := range g.excluded {
+		if compiled.MatchString(content) {
+			return pattern,g.excludes[i]
+		}
+	}
+	return pattern,""
+}
+
+func (g *SettingGroup) Match(content string) bool {
+	pattern,exclude := g.matchedPattern(content)
+	return pattern != "" && exclude == ""
 }
 
 func (g *SettingGroup) printTime() {
 	if g.minute == 0 {
-		fmt.Printf("%12s:\n",g.label)
+		fmt.Printf("%s:\n",schedule.PadLeft(g.label,12))
 	} else if g.minute < 60 {
-		fmt.Printf("%12s:             %2d minutes\n",g.label,g.minute)
+		fmt.Printf("%s:             %2d minutes\n",schedule.PadLeft(g.label,12),g.minute)
 	} else {
-		fmt.Printf("%12s: %5d hours %2d minutes\n",g.label,g.minute/60,g.minute%60)
+		fmt.Printf("%s: %5d hours %2d minutes\n",schedule.PadLeft(g.label,12),g.minute/60,g.minute%60)
 	}
 }
 
 func (g *SettingGroup) printTimePercent(total int) {
-	percent := fmt.Sprintf("%2.2f%%",float64(g.minute)/float64(total)*100)
-	if g.minute == 0 {
-		fmt.Printf("%12s:\n",g.label)
-	} else if g.minute < 60 {
-		fmt.Printf("%12s:             %2d minutes (%s)\n",g.label,g.minute,percent)
+	printTimePercent(g.label,g.minute,total)
+}
+
+func printTimePercent(label string,minute,total int) {
+	percent := fmt.Sprintf("%2.2f%%",float64(minute)/float64(total)*100)
+	label = schedule.PadLeft(label,12)
+	if minute == 0 {
+		fmt.Printf("%s:\n",label)
+	} else if minute < 60 {
+		fmt.Printf("%s:             %2d minutes (%s)\n",label,minute,percent)
 	} else {
-		fmt.Printf("%12s: %5d hours %2d minutes (%s)\n",g.label,g.minute/60,g.minute%60,percent)
+		fmt.Printf("%s: %5d hours %2d minutes (%s)\n",label,minute/60,minute%60,percent)
 	}
 }
 
@@ -114,10 +274,103 @@ func serializedSettingGroups(settingGroups map[string]*SettingGroup) (groups []*
 	return groups
 }
 
+// linkSettingGroups creates the missing parents of the groups and works out
+// the depth of every group in the hierarchy.
+func linkSettingGroups(settingGroups map[string]*SettingGroup) {
+	for _,group := range serializedSettingGroups(settingGroups) {
+		group.depth = 0
+		for g := group; g.parent != ""; g = settingGroups[g.parent] {
+			tryAddingNewGroup(settingGroups,g.parent)
+			group.depth += 1
+			fatalTruef(group.depth > len(settingGroups),"Cycle in parents of group %s",group.name)
+		}
+	}
+}
+
+// rollUpMinutes adds the minutes of every group to the totals of the group
+// and its parents.
+func rollUpMinutes(settingGroups map[string]*SettingGroup) {
+	for _,group := range settingGroups {
+		group.total = 0
+	}
+	for _,group := range settingGroups {
+		for g := group; g != nil; g = settingGroups[g.parent] {
+			g.total += group.minute
+		}
+	}
+}
+
+func hasHierarchy(settingGroups map[string]*SettingGroup) bool {
+	for _,group := range settingGroups {
+		if group.parent != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// treeSettingGroups orders the groups as a tree, every group followed by its
+// children, and the children of a group by their total time.
+func treeSettingGroups(settingGroups map[string]*SettingGroup) []*SettingGroup {
+	children := make(map[string][]*SettingGroup)
+	for _,group := range settingGroups {
+		children[group.parent] = append(children[group.parent],group)
+	}
+	groups := []*SettingGroup{}
+	var walk func(parent string)
+	walk = func(parent string) {
+		siblings := children[parent]
+		sort.SliceStable(siblings,func (i,j int) bool {
+			if siblings[i].total != siblings[j].total {
+				return siblings[i].total > siblings[j].total
+			}
+			return siblings[i].name < siblings[j].name
+		})
+		for _,group := range siblings {
+			groups = append(groups,group)
+			walk(group.name)
+		}
+	}
+	walk("")
+	return groups
+}
+
+// treeLabels indents the labels of the groups by their depth and pads them
+// to the same width, so that the times line up.
+func treeLabels(groups []*SettingGroup) (labels map[*SettingGroup]string,total string) {
+	labels = make(map[*SettingGroup]string)
+	width := 12
+	for _,group := range groups {
+		labels[group] = indent(group.depth)+group.label
+		if w := schedule.DisplayWidth(labels[group]); w > width {
+			width = w
+		}
+	}
+	for _,group := range groups {
+		labels[group] = schedule.PadRight(labels[group],width)
+	}
+	return labels,schedule.PadRight("Total",width)
+}
+
+func indent(depth int) string {
+	return strings.Repeat("  ",depth)
+}
+
+// orderedSettingGroups lists the groups in the order they were read from the
+// setting file, or added afterwards.
+func orderedSettingGroups(settingGroups map[string]*SettingGroup) []*SettingGroup {
+	groups := serializedSettingGroups(settingGroups)
+	sort.SliceStable(groups,func (i,j int) bool {
+		return groups[i].order < groups[j].order
+	})
+	return groups
+}
+
 func tryAddingNewGroup(settingGroups map[string]*SettingGroup,label string) {
 	_,ok = settingGroups[label]
 	if !ok {
 		settingGroups[label] = NewSettingGroup(label)
+		settingGroups[label].order = len(settingGroups)-1
 	}
 }
 
@@ -128,11 +381,41 @@ func compilePatterns(settingGroups map[string]*SettingGroup) {
 	}
 }
 
-func getItemGroup(content string,settingGroups map[string]*SettingGroup) *SettingGroup {
+// matchesBefore tells whether group g wins over group h when both match an
+// item: the higher priority wins, then the deeper group in the hierarchy,
+// then the group that comes first in the setting file.
+func (g *SettingGroup) matchesBefore(h *SettingGroup) bool {
+	if g.priority != h.priority {
+		return g.priority > h.priority
+	}
+	if g.depth != h.depth {
+		return g.depth > h.depth
+	}
+	return g.order < h.order
+}
+
+// matchingGroups lists the groups whose pattern matches the content, the
+// winning group first.
+func matchingGroups(content string,settingGroups map[string]*SettingGroup) []*SettingGroup {
+	groups := []*SettingGroup{}
+	for _,group := range settingGroups {
+		if group.Match(content) {
+			groups = append(groups,group)
+		}
+	}
+	sort.Slice(groups,func (i,j int) bool {
+		return groups[i].matchesBefore(groups[j])
+	})
+	return groups
+}
+
+func getItemGroup(content string,settingGroups map[string]*SettingGroup) (found *SettingGroup) {
 	for _,group := range settingGroups {
-		if group.compiled.MatchString(content) {
-			return group
+		if group.Match(content) {
+			if found == nil || group.matchesBefore(found) {
+				found = group
+			}
 		}
 	}
-	return nil
+	return found
 }
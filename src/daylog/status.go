@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"flag"
+	"io/ioutil"
+	"text/template"
+	"schedule"
+)
+
+// The exit codes of status tell a running job, no job, a start file that
+// cannot be read, and any other error apart.
+const (
+	STATUS_RUNNING = 0
+	STATUS_IDLE = 1
+	STATUS_CORRUPT = 2
+	STATUS_ERROR = 3
+)
+
+// Status is the running job as seen by the --format template of status.
+type Status struct {
+	Content string
+	Start string
+	Elapsed string
+	Minutes int
+	Group string
+	Slot string
+	Paused bool
+	Until string
+	Overdue string
+}
+
+func NewStatus(item *schedule.ScheduleItem) *Status {
+	status := &Status{item.ContentString(),item.StartString(),"",0,"",slot,item.Paused(),"",""}
+	if item.Planned() != nil {
+		status.Until = schedule.FormatTime(*item.Planned())
+	}
+	if overdue,_ := overdueFinish(item); overdue != nil {
+		status.Overdue = schedule.FormatTime(*overdue)
+	}
+	status.Minutes = runningMinutes(item)
+	status.Elapsed = fmt.Sprintf("%dh%dm",status.Minutes/60,status.Minutes%60)
+	compilePatterns(settingGroups)
+	group := getItemGroup(item.ContentString(),settingGroups)
+	if group == nil {
+		group = settingGroups["global"]
+	}
+	status.Group = group.label
+	return status
+}
+
+// runningMinutes counts the minutes the running job has taken so far,
+// leaving out its breaks.
+func runningMinutes(item *schedule.ScheduleItem) int {
+	running := *item
+	if !running.SetFinish(schedule.GetNow()) {
+		return 0
+	}
+	minute,_ := running.Duration()
+	return minute
+}
+
+func status() {
+	if flag.NArg() > 1 {
+		statusUsage()
+	}
+	var tmpl *template.Template
+	if format != "" {
+		var err error
+		tmpl,err = template.New("status").Parse(format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr,"Invalid format: %s\n",err.Error())
+			os.Exit(STATUS_ERROR)
+		}
+	}
+	data,err := ioutil.ReadFile(startPath)
+	if os.IsNotExist(err) {
+		if tmpl == nil {
+			fmt.Printf("%s\n",noScheduleStarted())
+		}
+		os.Exit(STATUS_IDLE)
+	}
+	if err == nil {
+		var item *schedule.ScheduleItem
+		item,err = schedule.RunningItemFromString(string(data))
+		if err == nil {
+			printStatus(NewStatus(item),tmpl)
+			os.Exit(STATUS_RUNNING)
+		}
+	}
+	fmt.Fprintf(os.Stderr,"Start file corrupted: %s: %s\n",startPath,err.Error())
+	os.Exit(STATUS_CORRUPT)
+}
+
+func printStatus(status *Status,tmpl *template.Template) {
+	if tmpl != nil {
+		err := tmpl.Execute(os.Stdout,status)
+		if err != nil {
+			fmt.Fprintf(os.Stderr,"Error writing status: %s\n",err.Error())
+			os.Exit(STATUS_ERROR)
+		}
+		fmt.Println()
+		return
+	}
+	state := "Running"
+	if status.Paused {
+		state = "Paused"
+	}
+	fmt.Printf("%s: %s\n",state,status.Content)
+	fmt.Printf("Since: %s\n",status.Start)
+	fmt.Printf("Elapsed: %s\n",status.Elapsed)
+	fmt.Printf("Group: %s\n",status.Group)
+	if status.Until != "" {
+		fmt.Printf("Until: %s\n",status.Until)
+	}
+	if status.Overdue != "" {
+		fmt.Printf("Overdue since %s, to finish then run: daylog finish %s\n",status.Overdue,status.Overdue)
+	}
+	printSlot()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"schedule"
+)
+
+const UNTAGGED = "(untagged)"
+
+type stringList []string
+
+func (list *stringList) String() string {
+	return strings.Join(*list,",")
+}
+
+func (list *stringList) Set(s string) error {
+	*list = append(*list,s)
+	return nil
+}
+
+var tagFilter stringList
+var statBy string
+
+// matchTagFilter tells whether the item has all the tags given by --tag.
+func matchTagFilter(item *schedule.ScheduleItem) bool {
+	for _,tag := range tagFilter {
+		if !item.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// statByTag counts the time of every tag. An item with several tags counts
+// toward each of them.
+func statByTag(startDay,toDay string) {
+	statByLabels(startDay,toDay,"tag",func (item *schedule.ScheduleItem) []string {
+		tags := item.Tags()
+		if len(tags) == 0 {
+			tags = []string{UNTAGGED}
+		}
+		return tags
+	})
+}
+
+// statByLabels counts the time of every label the items are given by
+// labelsOf, in the same way stat counts the time of every group.
+func statByLabels(startDay,toDay,by string,labelsOf func (*schedule.ScheduleItem) []string) {
+	totalMinutes,startCount := 0,false
+	minutes := make(map[string]int)
+	for _,day := range RangeDay(startDay,toDay) {
+		scheduleGroup := readScheduleGroupInDay(day)
+		for i := 0; i < scheduleGroup.Size(); i++ {
+			item,_ := scheduleGroup.Get(i)
+			if !matchTagFilter(item) {
+				continue
+			}
+			duration,_ := item.Duration()
+			for _,label := range labelsOf(item) {
+				minutes[label] += duration
+			}
+		}
+		if !startCount && !scheduleGroup.Empty() {
+			startCount = true
+			startDay = day
+		}
+		if startCount {
+			totalMinutes += MINUTES_IN_A_DAY
+		}
+	}
+	tags := make([]string,0,len(minutes))
+	for tag := range minutes {
+		tags = append(tags,tag)
+	}
+	sort.SliceStable(tags,func (i,j int) bool {
+		if minutes[tags[i]] != minutes[tags[j]] {
+			return minutes[tags[i]] > minutes[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+	if jsonOutput {
+		record := &StatRecord{startDay,toDay,[]*GroupTimeRecord{},totalMinutes}
+		for _,tag := range tags {
+			record.Groups = append(record.Groups,NewTimeRecord(tag,minutes[tag],totalMinutes))
+		}
+		writeJSON(record)
+		return
+	}
+	fmt.Printf("Statistics by %s from %s to %s:\n",by,startDay,toDay)
+	for _,tag := range tags {
+		printTimePercent(tag,minutes[tag],totalMinutes)
+	}
+	fmt.Printf("%12s: %5d hours %2d minutes\n","Total",totalMinutes/60,totalMinutes%60)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"sort"
+	"schedule"
 )
 
 type Task struct {
@@ -78,7 +79,7 @@ func (task *Task) String() string {
 }
 
 func (task *Task) Print() {
-	fmt.Printf("  %10s: level %3d, %s\n",task.name,task.level,task.content)
+	fmt.Printf("  %s: level %3d, %s\n",schedule.PadLeft(task.name,10),task.level,task.content)
 }
 
 func (task *Task) GetColor() string {
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"io"
+	"os"
+	"fmt"
+	"bufio"
+	"regexp"
+	"strings"
+	"schedule"
+)
+
+const TIMECLOCK_FORMAT = "2006/01/02 15:04:05"
+
+var timeclockPattern = regexp.MustCompile("^([ioO])\\s+(\\d\\d\\d\\d)[/-](\\d\\d)[/-](\\d\\d)\\s+(\\d\\d):(\\d\\d)(?::\\d\\d)?(?:\\s+(.*))?$")
+var timeclockSeparator = regexp.MustCompile("\\s\\s+|\\t")
+
+type clockIn struct {
+	start string
+	account string
+	description string
+}
+
+// writeTimeclock writes the items as clock-in and clock-out pairs, with the
+// group label as the account, followed by a clock-in for every running job.
+func writeTimeclock(items []*ItemRecord) {
+	for _,item := range items {
+		fmt.Printf("i %s %s  %s\n",timeclockTime(item.Start),itemGroupLabel(item.Content),item.Content)
+		fmt.Printf("o %s\n",timeclockTime(item.Finish))
+	}
+	for _,slot := range runningSlots() {
+		item := readStartItem(slot)
+		fmt.Printf("i %s %s  %s\n",timeclockTime(item.StartString()),
+			itemGroupLabel(item.ContentString()),item.ContentString())
+	}
+}
+
+func timeclockTime(s string) string {
+	t,err := schedule.GetTime(s)
+	fatalError("Invalid time",err)
+	return schedule.InDayZone(*t).Format(TIMECLOCK_FORMAT)
+}
+
+func itemGroupLabel(content string) string {
+	group := getItemGroup(content,settingGroups)
+	if group == nil {
+		group = settingGroups["global"]
+	}
+	return group.label
+}
+
+// readTimeclockItems reads clock-in and clock-out pairs as items, with the
+// description and the account as the content. A clock-out without an
+// account closes the latest clock-in. The clock-in left open, if any, is
+// returned as an unfinished item.
+func readTimeclockItems(r io.Reader) ([]*schedule.ScheduleItem,*schedule.ScheduleItem) {
+	items := []*schedule.ScheduleItem{}
+	open := []*clockIn{}
+	scanner := bufio.NewScanner(r)
+	for row := 1; scanner.Scan(); row++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.ContainsAny(line[:1],";#*") {
+			continue
+		}
+		groups := timeclockPattern.FindStringSubmatch(line)
+		fatalTruef(groups == nil,"Invalid timeclock line %d: %s",row,line)
+		t,ok := schedule.GetFullTime(fmt.Sprintf("%s.%s.%s/%s:%s",groups[2],groups[3],groups[4],groups[5],groups[6]))
+		fatalFalsef(ok,"Invalid time in timeclock line %d",row)
+		fields := timeclockSeparator.Split(strings.TrimSpace(groups[7]),2)
+		account := fields[0]
+		if groups[1] == "i" {
+			description := ""
+			if len(fields) > 1 {
+				description = fields[1]
+			}
+			open = append(open,&clockIn{t,account,description})
+			continue
+		}
+		index := len(open)-1
+		for i,in := range open {
+			if account != "" && in.account == account {
+				index = i
+			}
+		}
+		fatalTruef(index < 0,"Clock-out without clock-in in line %d",row)
+		in := open[index]
+		open = append(open[:index],open[index+1:]...)
+		items = append(items,newImportedItem(in.start,t,in.Content(),row))
+	}
+	fatalError("Error reading timeclock",scanner.Err())
+	for i := 0; i < len(open)-1; i++ {
+		fmt.Printf("Ignoring open clock-in at %s: %s\n",open[i].start,open[i].Content())
+	}
+	if len(open) == 0 {
+		return items,nil
+	}
+	in := open[len(open)-1]
+	item := schedule.NewScheduleItem()
+	fatalFalsef(item.SetStartString(in.start),"Invalid clock-in time: %s",in.start)
+	item.SetContent(in.Content())
+	return items,item
+}
+
+// Content is the description followed by the account, leaving out the
+// account when the description falls in its group anyway.
+func (in *clockIn) Content() string {
+	if in.description == "" {
+		return in.account
+	}
+	if in.account == "" || contentInGroup(in.description,in.account) {
+		return in.description
+	}
+	return in.description+" "+in.account
+}
+
+// startImportedItem writes an imported job that is still open, such as an
+// open clock-in, to the start file.
+func startImportedItem(item *schedule.ScheduleItem) {
+	fmt.Printf("Open job: %s\nAt Time: %s\n",item.ContentString(),item.StartString())
+	_,err := os.Stat(startPath)
+	running := err == nil
+	if running {
+		fmt.Printf("Task already started: %s\n",readStartItem(slot).ContentString())
+		fmt.Printf("Want to override it? (y/N)")
+	} else {
+		fmt.Printf("Start it? (Y/n)")
+	}
+	if !UserProceed(!running) {
+		return
+	}
+	WriteFile(startPath,item.RunningString())
+	fmt.Printf("Started: %s\n",item.ContentString())
+}
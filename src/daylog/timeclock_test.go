@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// setTestGroups sets up the groups global, work with pattern code, and
+// meet without a pattern.
+func setTestGroups() {
+	settingGroups = make(map[string]*SettingGroup)
+	for _,name := range []string{"global","work","meet"} {
+		tryAddingNewGroup(settingGroups,name)
+	}
+	settingGroups["work"].set("pattern","code")
+	compilePatterns(settingGroups)
+}
+
+func TestReadTimeclockItems(t *testing.T) {
+	setTestGroups()
+	data := strings.Join([]string{
+		"; a comment",
+		"i 2017/03/29 09:00:00 work  Java code",
+		"o 2017/03/29 10:30:00",
+		"",
+		"i 2017/03/29 11:00 meet",
+		"i 2017/03/29 11:15 work  Review",
+		"o 2017/03/29 11:45 meet",
+		"o 2017/03/29 12:00",
+		"i 2017-03-29 13:00:00 global  Go  lunch",
+	},"\n")
+	items,open := readTimeclockItems(strings.NewReader(data))
+	expects := []string{
+		"2017.03.29/09:00 2017.03.29/10:30 Java code",
+		"2017.03.29/11:00 2017.03.29/11:45 meet",
+		"2017.03.29/11:15 2017.03.29/12:00 Review work",
+	}
+	if len(items) != len(expects) {
+		t.Fatalf("readTimeclockItems() failed! Expect %d items, got %d\n",len(expects),len(items))
+	}
+	for i,expect := range expects {
+		if items[i].String() != expect {
+			t.Errorf("readTimeclockItems() failed! Expect %s, got %s\n",expect,items[i].String())
+		}
+	}
+	if open == nil || open.StartString() != "2017.03.29/13:00" || open.ContentString() != "Go  lunch" {
+		t.Errorf("readTimeclockItems() failed! Expect open item Go  lunch at 13:00, got %v\n",open)
+	}
+	_,open = readTimeclockItems(strings.NewReader("i 2017/03/29 09:00 work\no 2017/03/29 10:00\n"))
+	if open != nil {
+		t.Errorf("readTimeclockItems() failed! Expect no open item, got %s\n",open.String())
+	}
+}
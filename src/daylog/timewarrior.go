@@ -0,0 +1,133 @@
+package main
+
+import (
+	"io"
+	"fmt"
+	"time"
+	"bufio"
+	"regexp"
+	"strings"
+	"schedule"
+)
+
+const TIMEWARRIOR_FORMAT = "20060102T150405Z"
+
+var timewarriorPattern = regexp.MustCompile("^inc (\\d{8}T\\d{6}Z)(?: - (\\d{8}T\\d{6}Z))?(?: # (.*))?$")
+
+// writeTimewarrior writes the items as intervals tagged with the content and
+// the group label, followed by an open interval for every running job.
+func writeTimewarrior(items []*ItemRecord) {
+	for _,item := range items {
+		fmt.Printf("inc %s - %s # %s %s\n",timewarriorTime(item.Start),timewarriorTime(item.Finish),
+			timewarriorTag(item.Content),timewarriorTag(itemGroupLabel(item.Content)))
+	}
+	for _,slot := range runningSlots() {
+		item := readStartItem(slot)
+		fmt.Printf("inc %s # %s %s\n",timewarriorTime(item.StartString()),
+			timewarriorTag(item.ContentString()),timewarriorTag(itemGroupLabel(item.ContentString())))
+	}
+}
+
+func timewarriorTime(s string) string {
+	t,err := schedule.GetTime(s)
+	fatalError("Invalid time",err)
+	return schedule.Absolute(*t).UTC().Format(TIMEWARRIOR_FORMAT)
+}
+
+func timewarriorTag(tag string) string {
+	if tag != "" && !strings.ContainsAny(tag," \"\\") {
+		return tag
+	}
+	return "\""+strings.NewReplacer("\\","\\\\","\"","\\\"").Replace(tag)+"\""
+}
+
+// splitTimewarriorTags splits tags on spaces, keeping quoted tags whole.
+func splitTimewarriorTags(s string) []string {
+	tags := []string{}
+	tag,quoted,escaped,started := "",false,false,false
+	for _,c := range s {
+		switch {
+		case escaped:
+			tag += string(c)
+			escaped = false
+		case c == '\\' && quoted:
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+			started = true
+		case c == ' ' && !quoted:
+			if started {
+				tags = append(tags,tag)
+			}
+			tag,started = "",false
+		default:
+			tag += string(c)
+			started = true
+		}
+	}
+	if started {
+		tags = append(tags,tag)
+	}
+	return tags
+}
+
+// timewarriorContent joins the tags into the content. A tag naming a group
+// is left out only when the rest of the content falls in that group anyway.
+func timewarriorContent(tags []string) string {
+	rest := []string{}
+	for _,tag := range tags {
+		if !isGroupLabel(tag) {
+			rest = append(rest,tag)
+		}
+	}
+	content := []string{}
+	for _,tag := range tags {
+		if len(rest) == 0 || !isGroupLabel(tag) || !contentInGroup(strings.Join(rest," "),tag) {
+			content = append(content,tag)
+		}
+	}
+	return strings.Join(content," ")
+}
+
+func isGroupLabel(s string) bool {
+	for _,group := range settingGroups {
+		if group.label == s || group.name == s {
+			return true
+		}
+	}
+	return false
+}
+
+// readTimewarriorItems reads the intervals of a timewarrior data file. The
+// last open interval, if any, is returned as an unfinished item.
+func readTimewarriorItems(r io.Reader) ([]*schedule.ScheduleItem,*schedule.ScheduleItem) {
+	items := []*schedule.ScheduleItem{}
+	var open *schedule.ScheduleItem
+	scanner := bufio.NewScanner(r)
+	for row := 1; scanner.Scan(); row++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		groups := timewarriorPattern.FindStringSubmatch(line)
+		fatalTruef(groups == nil,"Invalid timewarrior line %d: %s",row,line)
+		tags := strings.SplitN(groups[3]," # ",2)[0]
+		content := timewarriorContent(splitTimewarriorTags(tags))
+		start := timewarriorFullTime(groups[1],row)
+		if groups[2] == "" {
+			open = schedule.NewScheduleItem()
+			fatalFalsef(open.SetStartString(start),"Invalid start time in line %d",row)
+			open.SetContent(content)
+			continue
+		}
+		items = append(items,newImportedItem(start,timewarriorFullTime(groups[2],row),content,row))
+	}
+	fatalError("Error reading timewarrior data",scanner.Err())
+	return items,open
+}
+
+func timewarriorFullTime(s string,row int) string {
+	t,err := time.Parse(TIMEWARRIOR_FORMAT,s)
+	fatalErrorf(err,"Invalid time in line %d",row)
+	return schedule.FormatTime(t.Local())
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitTimewarriorTags(t *testing.T) {
+	tests := []struct {
+		s string
+		tags []string
+	}{
+		{"",[]string{}},
+		{"Java work",[]string{"Java","work"}},
+		{"  Java   work ",[]string{"Java","work"}},
+		{"\"Write code\" work",[]string{"Write code","work"}},
+		{"\"say \\\"hi\\\"\" \"a\\\\b\"",[]string{"say \"hi\"","a\\b"}},
+		{"\"\" work",[]string{"","work"}},
+	}
+	for _,test := range tests {
+		tags := splitTimewarriorTags(test.s)
+		if strings.Join(tags,"|") != strings.Join(test.tags,"|") || len(tags) != len(test.tags) {
+			t.Errorf("splitTimewarriorTags(%q) failed! Expect %q, got %q\n",test.s,test.tags,tags)
+		}
+	}
+}
+
+func TestTimewarriorTag(t *testing.T) {
+	for _,tag := range []string{"Java","Write code","say \"hi\"","a\\b",""} {
+		tags := splitTimewarriorTags(timewarriorTag(tag)+" "+timewarriorTag("work"))
+		if len(tags) != 2 || tags[0] != tag || tags[1] != "work" {
+			t.Errorf("timewarriorTag(%q) failed! Got %q back\n",tag,tags)
+		}
+	}
+}
+
+func TestTimewarriorContent(t *testing.T) {
+	setTestGroups()
+	tests := []struct {
+		tags []string
+		content string
+	}{
+		{[]string{"meeting","work"},"meeting work"},
+		{[]string{"code","work"},"code"},
+		{[]string{"work","code"},"code"},
+		{[]string{"lunch","global"},"lunch"},
+		{[]string{"standup","meet"},"standup meet"},
+		{[]string{"work"},"work"},
+	}
+	for _,test := range tests {
+		content := timewarriorContent(test.tags)
+		if content != test.content {
+			t.Errorf("timewarriorContent(%q) failed! Expect %s, got %s\n",test.tags,test.content,content)
+		}
+	}
+}
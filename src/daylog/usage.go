@@ -8,18 +8,37 @@ import (
 
 const (
 	USAGE = "Usage: daylog [options] command [args]"
-	SETTING_USAGE = "Usage: daylog [options] set {help | key | key=value}"
-	START_USAGE = "Usage: daylog [options] start [help]|[content [time]]"
-	RESTART_USAGE = "Usage: daylog [options] restart [help]|[content]|[time]"
-	CANCEL_USAGE = "Usage: daylog [options] cancel [help]"
-	FINISH_USAGE = "Usage: daylog [options] finish [help]|[time]"
-	STAT_USAGE = "Usage: daylog [options] stat [help]|[startday [endday]]"
-	LIST_USAGE = "Usage: daylog [options] list [help]|[startday [endday]]"
+	SETTING_USAGE = "Usage: daylog [options] set {help | key | key=value | key+=pattern | key-=pattern}"
+	START_USAGE = "Usage: daylog [options] start [help]|[--slot name] [--for 45m] [--attr key=value] [content [time]]"
+	RESTART_USAGE = "Usage: daylog [options] restart [help]|[--slot name] [content]|[time]"
+	CANCEL_USAGE = "Usage: daylog [options] cancel [help]|[--slot name]"
+	FINISH_USAGE = "Usage: daylog [options] finish [help]|[--slot name] [--note note] [--attr key=value] [time]"
+	ADD_USAGE = "Usage: daylog [options] add [help]|[content from to]"
+	EDIT_USAGE = "Usage: daylog [options] edit [help]|[day [index {start time|finish time|content content|attr key=value|delete|split time}]]"
+	PAUSE_USAGE = "Usage: daylog [options] pause [help]|[--slot name] [time]"
+	RESUME_USAGE = "Usage: daylog [options] resume [help]|[--slot name] [time]"
+	STAT_USAGE = "Usage: daylog [options] stat [help]|[--json] [--by group|tag|attr] [--tag tag] [startday [endday]]"
+	POMODORO_USAGE = "Usage: daylog [options] pomodoro [help]|[--slot name] [--work 25m] [--break 5m] [--cycles 4] content"
+	NOTE_USAGE = "Usage: daylog [options] note [help]|[--slot name] [note]"
+	STATUS_USAGE = "Usage: daylog [options] status [help]|[--slot name] [--format template]\n" +
+		"template fields: {{.Content}} {{.Start}} {{.Elapsed}} {{.Minutes}} {{.Group}} {{.Slot}} {{.Paused}} {{.Until}} {{.Overdue}}\n" +
+		"exit code: 0 running, 1 idle, 2 start file corrupted, 3 other error"
+	LIST_USAGE = "Usage: daylog [options] list [help]|[--tag tag] [--where attr=value] [--notes] [--search pattern] [startday [endday]]"
+	SEARCH_USAGE = "Usage: daylog [options] search [help]|[--fixed] [--notes] pattern"
 	PLOT_USAGE = "Usage: daylog [options] plot [help]|[startday [endday]]"
 	DRAW_USAGE = "Usage: daylog [options] draw [help]|[startday [endday]]"
-	JOB_USAGE = "Usage: daylog [options] job [help]|[startday [endday]]"
-	JOBSTAT_USAGE = "Usage: daylog [options] jobstat [help]|[startday [endday]]"
+	GOALS_USAGE = "Usage: daylog [options] goals [help]|[startday [endday]]"
+	EXPLAIN_USAGE = "Usage: daylog [options] explain [help]|[content]"
+	JOB_USAGE = "Usage: daylog [options] job [help]|[--json] [--tag tag] [startday [endday]]"
+	JOBSTAT_USAGE = "Usage: daylog [options] jobstat [help]|[--json] [--tag tag] [startday [endday]]"
+	EXPORT_USAGE = "Usage: daylog [options] export [help]|[--format json|csv|timeclock|timewarrior] [startday [endday]]"
+	ICS_USAGE = "Usage: daylog [options] ics [help]|[startday [endday]]"
+	IMPORT_USAGE = "Usage: daylog [options] import [help]|[--format csv|timeclock|timewarrior] file|directory"
+	CHECK_USAGE = "Usage: daylog [options] check [help]|[--fix]"
 	TASK_USAGE = "Usage: daylog [options] task [help]|[set taskname level|content]"
+	DAY_USAGE = "day: 2017.03.14, 03.14, today, yesterday, -3d, -2w, last monday\n" +
+		"range: this week, last week, this month, last month, this year, last year, 2017.03, 2017, 2017.W14\n" +
+		"a range as startday starts with its first day, as endday ends with its last; weeks start on week_start"
 )
 
 func usage() {
@@ -32,13 +51,27 @@ func usage() {
 	fmt.Println("  restart restart the job")
 	fmt.Println("  cancel  cancel the started job")
 	fmt.Println("  finish  finish the current job or prolong the last finished job")
+	fmt.Println("  add     add a finished job")
+	fmt.Println("  edit    list or correct the jobs of a day")
+	fmt.Println("  pause   take a break from the current job")
+	fmt.Println("  resume  resume the current job after a break")
+	fmt.Println("  pomodoro work on a job in pomodoro intervals")
+	fmt.Println("  note    show or add to the note of the current job")
+	fmt.Println("  status  show the current job")
 	fmt.Println("  list    list jobs")
+	fmt.Println("  search  search the jobs of all days")
 	fmt.Println("  stat    show statistic")
 	fmt.Println("  plot    plot time usage")
 	fmt.Println("  draw    draw time usage")
+	fmt.Println("  goals   show the daily and weekly targets hit and missed")
+	fmt.Println("  explain show the groups matching a job")
 	fmt.Println("  job     show jobs present")
 	fmt.Println("  jobstat sort jobs by last time")
+	fmt.Println("  export  export jobs")
+	fmt.Println("  import  import jobs")
+	fmt.Println("  ics     export jobs as icalendar")
 	fmt.Println("  task    show tasks or set task attribuets")
+	fmt.Println("  check   check the data files for problems")
 	os.Exit(0)
 }
 
@@ -67,33 +100,112 @@ func finishUsage() {
 	os.Exit(0)
 }
 
+func addUsage() {
+	fmt.Println(ADD_USAGE)
+	os.Exit(0)
+}
+
+func editUsage() {
+	fmt.Println(EDIT_USAGE)
+	os.Exit(0)
+}
+
+func pauseUsage() {
+	fmt.Println(PAUSE_USAGE)
+	os.Exit(0)
+}
+
+func resumeUsage() {
+	fmt.Println(RESUME_USAGE)
+	os.Exit(0)
+}
+
 func statUsage() {
 	fmt.Println(STAT_USAGE)
+	fmt.Println(DAY_USAGE)
+	os.Exit(0)
+}
+
+func pomodoroUsage() {
+	fmt.Println(POMODORO_USAGE)
+	os.Exit(0)
+}
+
+func noteUsage() {
+	fmt.Println(NOTE_USAGE)
+	os.Exit(0)
+}
+
+func statusUsage() {
+	fmt.Println(STATUS_USAGE)
 	os.Exit(0)
 }
 
 func listUsage() {
 	fmt.Println(LIST_USAGE)
+	fmt.Println(DAY_USAGE)
+	os.Exit(0)
+}
+
+func searchUsage() {
+	fmt.Println(SEARCH_USAGE)
 	os.Exit(0)
 }
 
 func plotUsage() {
 	fmt.Println(PLOT_USAGE)
+	fmt.Println(DAY_USAGE)
 	os.Exit(0)
 }
 
 func drawUsage() {
 	fmt.Println(DRAW_USAGE)
+	fmt.Println(DAY_USAGE)
+	os.Exit(0)
+}
+
+func goalsUsage() {
+	fmt.Println(GOALS_USAGE)
+	fmt.Println(DAY_USAGE)
+	os.Exit(0)
+}
+
+func explainUsage() {
+	fmt.Println(EXPLAIN_USAGE)
 	os.Exit(0)
 }
 
 func jobUsage() {
 	fmt.Println(JOB_USAGE)
+	fmt.Println(DAY_USAGE)
 	os.Exit(0)
 }
 
 func jobstatUsage() {
 	fmt.Println(JOBSTAT_USAGE)
+	fmt.Println(DAY_USAGE)
+	os.Exit(0)
+}
+
+func checkUsage() {
+	fmt.Println(CHECK_USAGE)
+	os.Exit(0)
+}
+
+func exportUsage() {
+	fmt.Println(EXPORT_USAGE)
+	fmt.Println(DAY_USAGE)
+	os.Exit(0)
+}
+
+func importUsage() {
+	fmt.Println(IMPORT_USAGE)
+	os.Exit(0)
+}
+
+func icsUsage() {
+	fmt.Println(ICS_USAGE)
+	fmt.Println(DAY_USAGE)
 	os.Exit(0)
 }
 
@@ -4,13 +4,13 @@ import (
 	"log"
 	"os"
 	"fmt"
-	"flag"
 	"os/user"
 	"path/filepath"
 	"schedule"
 	"bufio"
 	"io/ioutil"
 	"regexp"
+	"time"
 	"image/color"
 	"image/draw"
 	"image/png"
@@ -22,6 +22,20 @@ const (
 	COLUMNS int = MINUTES_IN_A_DAY/ROWS
 )
 
+// fatalExitCode is the exit code of a fatal error, 1 unless the command
+// gives its own exit codes a meaning.
+var fatalExitCode = 1
+
+func logFatalf(s string,v ...interface{}) {
+	log.Printf(s,v...)
+	os.Exit(fatalExitCode)
+}
+
+func logFatal(s string) {
+	log.Print(s)
+	os.Exit(fatalExitCode)
+}
+
 func EvalPath(p string) string {
 	if p[:2] == "~/" {
 		usr,_ := user.Current()
@@ -34,13 +48,13 @@ func EvalPath(p string) string {
 func fatalErrorf(err error,s string,v ...interface{}) {
 	if err != nil {
 		s = fmt.Sprintf(s,v...)
-		log.Fatalf("%s: %s\n",s,err.Error())
+		logFatalf("%s: %s\n",s,err.Error())
 	}
 }
 
 func fatalTruef(b bool,s string,v ...interface{}) {
 	if b {
-		log.Fatalf(s,v...)
+		logFatalf(s,v...)
 	}
 }
 
@@ -50,23 +64,23 @@ func fatalFalsef(b bool,s string,v ...interface{}) {
 
 func fatalError(s string,err error) {
 	if err != nil {
-		log.Fatalf("%s: %s\n",s,err.Error())
+		logFatalf("%s: %s\n",s,err.Error())
 	}
 }
 
 func fatalNotFileNotExistError(err error) {
 	if err != nil && !os.IsNotExist(err) {
-		log.Fatalf(err.Error())
+		logFatal(err.Error())
 	}
 }
 
 func fatal(s string) {
-	log.Fatal(s)
+	logFatal(s)
 }
 
 func fatalTrue(b bool,s string) {
 	if b {
-		log.Fatal(s)
+		logFatal(s)
 	}
 }
 
@@ -81,15 +95,99 @@ func readScheduleGroupByDay(day string) *schedule.ScheduleGroup {
 	return scheduleGroup
 }
 
+// readScheduleGroupInDay reads the items in the day, split at midnight, so
+// that items continuing from the day before are included and items running
+// into the next day are cut.
+func readScheduleGroupInDay(day string) *schedule.ScheduleGroup {
+	dayBefore,err := schedule.DayAddString(day,-1)
+	fatalError("Invalid day "+day,err)
+	inDay := schedule.NewScheduleGroup()
+	for _,d := range []string{dayBefore,day} {
+		scheduleGroup := readScheduleGroupByDay(d)
+		for i := 0; i < scheduleGroup.Size(); i++ {
+			item,_ := scheduleGroup.Get(i)
+			part,err := item.DayPart(day)
+			fatalError("Invalid item in day "+d,err)
+			if part != nil {
+				inDay.Add(part)
+			}
+		}
+	}
+	return inDay
+}
+
+func readStartItem(slot string) *schedule.ScheduleItem {
+	p := startPathOf(slot)
+	startFile,err := ioutil.ReadFile(p)
+	fatalError("Error reading start file",err)
+	item,err := schedule.RunningItemFromString(string(startFile))
+	fatalError("Start file corrupted: "+p,err)
+	return item
+}
+
+// readRunningItem reads the item running in the current slot.
+func readRunningItem() *schedule.ScheduleItem {
+	_,err := os.Stat(startPath)
+	fatalNotFileNotExistError(err)
+	fatalFalse(err==nil,noScheduleStarted())
+	return readStartItem(slot)
+}
+
+// addScheduleItems files the items into the day files of their start days.
+func addScheduleItems(items []*schedule.ScheduleItem) {
+	groups := make(map[string]*schedule.ScheduleGroup)
+	days := []string{}
+	for _,item := range items {
+		day := item.StartDayString()
+		if _,ok := groups[day]; !ok {
+			readScheduleGroups(groups,day)
+			days = append(days,day)
+		}
+		groups[day].Add(item)
+	}
+	writeScheduleGroups(groups,days)
+}
+
+// readScheduleGroups reads the days not read yet into the groups, so that
+// all the day files a change touches are read before any is written.
+func readScheduleGroups(groups map[string]*schedule.ScheduleGroup,days ...string) {
+	for _,day := range days {
+		if _,ok := groups[day]; !ok {
+			groups[day] = readScheduleGroupByDay(day)
+		}
+	}
+}
+
+func writeScheduleGroups(groups map[string]*schedule.ScheduleGroup,days []string) {
+	for _,day := range days {
+		groups[day].Sort()
+		WriteFile(filepath.Join(path,day),groups[day].StringOfDay(day))
+	}
+}
+
+func noScheduleStarted() string {
+	if slot != "" {
+		return "No schedule started in slot "+slot+"!"
+	}
+	return "No schedule started yet!"
+}
+
+func printSlot() {
+	if slot != "" {
+		fmt.Printf("Slot: %s\n",slot)
+	}
+}
+
 func evalDay(day string) (string,bool) {
 	if day == "today" {
 		return schedule.GetTodayString(),true
 	} else if day == "yesterday" {
 		return schedule.GetYesterdayString(),true
+	} else if relative,ok := evalRelativeDay(day); ok {
+		return relative,true
 	} else {
 		return schedule.FullDayString(day)
 	}
-	return day,false
 }
 
 func RangeDay(startDay,toDay string) []string {
@@ -108,13 +206,26 @@ func RangeDay(startDay,toDay string) []string {
 func ExpandTime(s string) string {
 	t,ok := schedule.GetFullTime(s)
 	if !ok {
-		log.Fatalf("Invalid time: %s\n",s)
+		logFatalf("Invalid time: %s\n",s)
 	}
 	return t
 }
 
+// ExpandTimeInDay expands a clock time to a time in the given day.
+func ExpandTimeInDay(s,day string) string {
+	t,ok := schedule.GetFullTime(day+"/"+s)
+	if ok {
+		return t
+	}
+	return ExpandTime(s)
+}
+
+var stdin *bufio.Reader
+
 func UserProceed(deft bool) bool {
-	stdin := bufio.NewReader(os.Stdin)
+	if stdin == nil {
+		stdin = bufio.NewReader(os.Stdin)
+	}
 	c,_ := stdin.ReadString('\n')
 	if c == "" {
 		return deft
@@ -144,7 +255,7 @@ func SplitFileByLine(filename string) ([]string,bool) {
 	data,err := ioutil.ReadFile(filename)
 	if err != nil {
 		if !os.IsNotExist(err) {
-			log.Fatalf(err.Error())
+			logFatal(err.Error())
 		}
 		Verbose(1,"File %s not exist, use default\n",filename)
 		return []string{},false
@@ -162,8 +273,6 @@ func ExpandPossibleEmptyToToday(t string) string {
 		fatalFalse(ok,"Invalid time "+t)
 		return day
 	}
-	log.Fatalf("Invalid time %s!",t)
-	return ""
 }
 
 func ExpandPossibleEmptyToNow(t string) string {
@@ -173,8 +282,6 @@ func ExpandPossibleEmptyToNow(t string) string {
 		fatalFalse(schedule.IsTimeString(t),"Invalid time "+t)
 		return t
 	}
-	log.Fatalf("Invalid time %s!",t)
-	return ""
 }
 
 func CheckScheduleExist(day string) bool {
@@ -236,6 +343,12 @@ func getColor(name string) color.Color {
 }
 
 func fillColor(colorArray []color.Color,item *schedule.ScheduleItem,c color.Color) {
+	for _,segment := range item.Segments() {
+		fillSegmentColor(colorArray,segment,c)
+	}
+}
+
+func fillSegmentColor(colorArray []color.Color,item *schedule.ScheduleItem,c color.Color) {
 	from := item.StartMinute()
 	to := item.FinishMinute()
 	fatalTruef(from < 0 || from >= len(colorArray),"Invalid start time %d",from)
@@ -335,13 +448,56 @@ func drawColorArray(colorArray []color.Color,size int,imagename string) {
 	if err != nil {
 		fatalError("Error opening image to write",err)
 	}
-	encoder := &png.Encoder{0}
+	encoder := &png.Encoder{CompressionLevel: 0}
 	err = encoder.Encode(writer,m)
 	if err != nil {
 		fatalError("Error encoding image into png",err)
 	}
 }
 
+func maxItemMinutesFromConfiguration() int {
+	var maxMinutes int
+	maxMinutesS,ok := configuration["max_item_minutes"]
+	_,err := fmt.Sscanf(maxMinutesS,"%d",&maxMinutes)
+	if !ok || err != nil || maxMinutes < 0 {
+		return 0
+	}
+	return maxMinutes
+}
+
+// overdueFinish finds the time a running item should have been finished
+// at, if that time has passed: its planned finish time, or its start time
+// plus max_item_minutes, whichever is earlier.
+func overdueFinish(item *schedule.ScheduleItem) (finish *time.Time,reason string) {
+	now := schedule.GetNow()
+	if planned := item.Planned(); planned != nil && planned.Before(*now) {
+		finish,reason = planned,"planned finish time"
+	}
+	if maxMinutes := maxItemMinutesFromConfiguration(); maxMinutes > 0 {
+		capped := item.Start().Add(time.Duration(maxMinutes)*time.Minute)
+		if capped.Before(*now) && (finish == nil || capped.Before(*finish)) {
+			finish,reason = &capped,fmt.Sprintf("limit of %d minutes",maxMinutes)
+		}
+	}
+	return
+}
+
+// offerOverdueFinish asks whether to finish an overdue item at the time it
+// should have been finished at instead of the given time.
+func offerOverdueFinish(item *schedule.ScheduleItem,finishTime string) string {
+	overdue,reason := overdueFinish(item)
+	if overdue == nil {
+		return finishTime
+	}
+	overdueTime := schedule.FormatTime(*overdue)
+	fmt.Printf("Running past its %s: %s\n",reason,overdueTime)
+	fmt.Printf("Finish at %s instead? (Y/n)",overdueTime)
+	if UserProceed(true) {
+		return overdueTime
+	}
+	return finishTime
+}
+
 func statDayFromConfiguration() int {
 	var statLength int
 	statLengthS,ok := configuration["stat_day"]
@@ -352,17 +508,21 @@ func statDayFromConfiguration() int {
 	return statLength
 }
 
+// evalDayPairByCommand takes the start day and the end day from the command,
+// where each may be a range such as this week or 2017.03: the start day
+// starts the first range and the end day ends the last one.
 func evalDayPairByCommand(startDay,toDay string) (start,to string) {
-	if flag.NArg() > 1 {
-		startDay = flag.Arg(1)
+	args := dayArgs()
+	if len(args) > 0 {
+		startDay = args[0]
 		toDay = startDay
 	}
-	if flag.NArg() > 2 {
-		toDay = flag.Arg(2)
+	if len(args) > 1 {
+		toDay = args[1]
 	}
 	var ok1,ok2 bool
-	start,ok1 = evalDay(startDay)
-	to,ok2 = evalDay(toDay)
+	start,_,ok1 = evalDayRange(startDay)
+	_,to,ok2 = evalDayRange(toDay)
 	fatalFalsef(ok1,"Invalid start day: %s",startDay)
 	fatalFalsef(ok2,"Invalid end day: %s",toDay)
 	fatalFalse(schedule.DayNotAfterString(start,to),"Start day is later than end day!")
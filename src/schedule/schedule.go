@@ -4,9 +4,11 @@ import (
 	"os"
 	"fmt"
 	"time"
+	"sort"
 	"regexp"
 	"errors"
 	"strings"
+	"unicode"
 	"io/ioutil"
 )
 
@@ -16,50 +18,182 @@ const FORMAT_DAY_CLOCK string = "01.02/15:04"
 const FORMAT_DAY string = "2006.01.02"
 const FORMAT_DAY_WEEK string = "2006.01.02 Mon"
 const FORMAT_ONLY_DAY string = "01.02"
+const FORMAT_ZONE string = "-07:00"
+const NOTE_PREFIX string = "> "
+const ATTRIBUTE_PREFIX string = ": "
 var itemPattern *regexp.Regexp
+var breakPattern *regexp.Regexp
+var plannedPattern *regexp.Regexp
+var attributePattern = regexp.MustCompile("^(\\w+)=(\\S*)$")
+var tagPattern *regexp.Regexp
+var timePattern *regexp.Regexp
+var offsetPattern *regexp.Regexp
+
+// zone is the zone days are bucketed in. When it is nil, times without an
+// offset are read as naive wall clock times, as daylog has always done.
+var zone *time.Location
+
+// SetZone configures the zone used to bucket days. The name may be an IANA
+// zone, "Local", or a UTC offset like "+08:00". An empty name restores the
+// naive behaviour where no offsets are written.
+func SetZone(name string) error {
+	if name == "" {
+		zone = nil
+		return nil
+	}
+	loc,err := LoadZone(name)
+	if err != nil {
+		return err
+	}
+	zone = loc
+	return nil
+}
+
+func LoadZone(name string) (*time.Location,error) {
+	if name == "Local" || name == "local" {
+		return time.Local,nil
+	}
+	if offsetPattern == nil {
+		offsetPattern = regexp.MustCompile("^([+-])(\\d\\d):?(\\d\\d)$")
+	}
+	if name == "Z" {
+		return time.UTC,nil
+	}
+	if offsetPattern.MatchString(name) {
+		groups := offsetPattern.FindStringSubmatch(name)
+		hour,minute := 0,0
+		fmt.Sscanf(groups[2]+" "+groups[3],"%d %d",&hour,&minute)
+		offset := hour*3600+minute*60
+		if groups[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(groups[1]+groups[2]+":"+groups[3],offset),nil
+	}
+	return time.LoadLocation(name)
+}
+
+func ZoneEnabled() bool {
+	return zone != nil
+}
+
+// dayZone is the zone in which days start and end.
+func dayZone() *time.Location {
+	if zone == nil {
+		return time.UTC
+	}
+	return zone
+}
+
+// inputZone is the zone of clock times typed without an offset.
+func inputZone() *time.Location {
+	if zone == nil {
+		return time.UTC
+	}
+	return time.Local
+}
+
+func now() time.Time {
+	n := time.Now()
+	if zone == nil {
+		return time.Date(n.Year(),n.Month(),n.Day(),n.Hour(),n.Minute(),0,0,time.UTC)
+	}
+	return n.Truncate(time.Minute)
+}
+
+// ParseTime reads a time in FORMAT with an optional offset suffix. Times
+// without an offset are read in the day zone.
+func ParseTime(s string) (time.Time,error) {
+	return parseTimeIn(s,dayZone())
+}
+
+func parseTimeIn(s string,loc *time.Location) (time.Time,error) {
+	if timePattern == nil {
+		timePattern = regexp.MustCompile("^(\\d\\d\\d\\d\\.\\d\\d\\.\\d\\d/\\d\\d:\\d\\d)(Z|[+-]\\d\\d:\\d\\d)?$")
+	}
+	groups := timePattern.FindStringSubmatch(s)
+	if groups == nil {
+		return time.Time{},errors.New("Invalid time: "+s)
+	}
+	if groups[2] == "" {
+		return time.ParseInLocation(FORMAT,groups[1],loc)
+	}
+	return time.Parse(FORMAT+"Z07:00",s)
+}
+
+// FormatTime writes a time in FORMAT, followed by its UTC offset when a
+// zone is configured.
+func FormatTime(t time.Time) string {
+	if zone == nil {
+		return t.Format(FORMAT)
+	}
+	return t.Format(FORMAT+FORMAT_ZONE)
+}
+
+// InDayZone converts a time to the day zone. Without a zone the wall clock
+// of the time is kept as it is.
+func InDayZone(t time.Time) time.Time {
+	if zone == nil {
+		return t
+	}
+	return t.In(zone)
+}
+
+// Absolute gives the instant of a time. Without a zone the wall clock of
+// the time is taken as local time.
+func Absolute(t time.Time) time.Time {
+	if zone == nil {
+		return time.Date(t.Year(),t.Month(),t.Day(),t.Hour(),t.Minute(),t.Second(),0,time.Local)
+	}
+	return t
+}
+
+func dayOf(t time.Time) time.Time {
+	t = InDayZone(t)
+	return time.Date(t.Year(),t.Month(),t.Day(),0,0,0,0,t.Location())
+}
 
 func GetFullTime(s string) (string,bool) {
-	t,err := time.Parse(FORMAT,s)
+	t,err := parseTimeIn(s,inputZone())
 	if err == nil {
-		return t.Format(FORMAT),true
+		return FormatTime(t),true
 	}
 	t,err = time.Parse(FORMAT_CLOCK,s)
 	if err == nil {
-		now := time.Now()
-		t = time.Date(now.Year(),now.Month(),now.Day(),t.Hour(),t.Minute(),0,0,time.UTC)
-		return t.Format(FORMAT),true
+		now := now().In(inputZone())
+		t = time.Date(now.Year(),now.Month(),now.Day(),t.Hour(),t.Minute(),0,0,inputZone())
+		return FormatTime(t),true
 	}
 	t,err = time.Parse(FORMAT_DAY_CLOCK,s)
 	if err == nil {
-		now := time.Now()
-		t = time.Date(now.Year(),t.Month(),t.Day(),t.Hour(),t.Minute(),0,0,time.UTC)
-		return t.Format(FORMAT),true
+		now := now().In(inputZone())
+		t = time.Date(now.Year(),t.Month(),t.Day(),t.Hour(),t.Minute(),0,0,inputZone())
+		return FormatTime(t),true
 	}
-	t,err = time.Parse(FORMAT_DAY,s)
+	t,err = time.ParseInLocation(FORMAT_DAY,s,dayZone())
 	if err == nil {
-		return t.Format(FORMAT),true
+		return FormatTime(t),true
 	}
 	t,err = time.Parse(FORMAT_ONLY_DAY,s)
 	if err == nil {
-		now := time.Now()
-		t = time.Date(now.Year(),t.Month(),t.Day(),t.Hour(),t.Minute(),0,0,time.UTC)
-		return t.Format(FORMAT),true
+		now := InDayZone(now())
+		t = time.Date(now.Year(),t.Month(),t.Day(),t.Hour(),t.Minute(),0,0,dayZone())
+		return FormatTime(t),true
 	}
 	return "",false
 }
 
 func GetDayString(s string) (string,bool) {
-	t,err := time.Parse(FORMAT,s)
+	t,err := ParseTime(s)
 	if err == nil {
-		return t.Format(FORMAT_DAY),true
+		return InDayZone(t).Format(FORMAT_DAY),true
 	}
 	return "",false
 }
 
 func GetDayWeekString(s string) (string,bool) {
-	t,err := time.Parse(FORMAT,s)
+	t,err := ParseTime(s)
 	if err == nil {
-		return t.Format(FORMAT_DAY_WEEK),true
+		return InDayZone(t).Format(FORMAT_DAY_WEEK),true
 	}
 	t,err = time.Parse(FORMAT_ONLY_DAY,s)
 	if err == nil {
@@ -78,7 +212,7 @@ func IsDayString(s string) bool {
 }
 
 func IsTimeString(s string) bool {
-	_,err := time.Parse(FORMAT,s)
+	_,err := ParseTime(s)
 	return err == nil
 }
 
@@ -109,11 +243,11 @@ func CompareDayString(start,to string) int {
 }
 
 func CompareTimeString(start,to string) int {
-	startTime,err := time.Parse(FORMAT,start)
+	startTime,err := ParseTime(start)
 	if err != nil {
 		return -2
 	}
-	toTime,err := time.Parse(FORMAT,to)
+	toTime,err := ParseTime(to)
 	if err != nil {
 		return -2
 	}
@@ -145,32 +279,30 @@ func DayAddString(s string,d int) (string,error) {
 }
 
 func GetNowString() string {
-	now := time.Now()
-	return now.Format(FORMAT)
+	return FormatTime(now())
 }
 
 func GetTodayString() string {
-	now := time.Now()
-	return now.Format(FORMAT_DAY)
+	return InDayZone(now()).Format(FORMAT_DAY)
 }
 
 func GetYesterdayString() string {
-	now := time.Now()
+	now := InDayZone(now())
 	now = now.AddDate(0,0,-1)
 	return now.Format(FORMAT_DAY)
 }
 
 func GetNow() *time.Time {
-	now,_ := time.Parse(FORMAT,GetNowString())
+	now := now()
 	return &now
 }
 
 func GetRange(s,t string) (from,to *time.Time, err error) {
-	ff,err := time.Parse(FORMAT_DAY,s)
+	ff,err := time.ParseInLocation(FORMAT_DAY,s,dayZone())
 	if err != nil {
 		return nil,nil,err
 	}
-	tt,err := time.Parse(FORMAT_DAY,t)
+	tt,err := time.ParseInLocation(FORMAT_DAY,t,dayZone())
 	if err != nil {
 		return nil,nil,err
 	}
@@ -179,7 +311,7 @@ func GetRange(s,t string) (from,to *time.Time, err error) {
 }
 
 func GetTime(s string) (*time.Time,error) {
-	t,err := time.Parse(FORMAT,s)
+	t,err := ParseTime(s)
 	if err != nil {
 		return nil,err
 	}
@@ -194,16 +326,28 @@ type ScheduleItem struct {
 	start *time.Time
 	finish *time.Time
 	content string
+	breaks []*interval
+	planned *time.Time
+	attributes map[string]string
+	note []string
+	continued bool
+}
+
+// interval is a break taken while an item is running. A break without a
+// finish time is still going on.
+type interval struct {
+	start *time.Time
+	finish *time.Time
 }
 
 func NewScheduleItem() (item *ScheduleItem) {
-	item = &ScheduleItem{nil,nil,""}
+	item = &ScheduleItem{nil,nil,"",nil,nil,nil,nil,false}
 	return item
 }
 
 func ScheduleItemNow(content string) (item *ScheduleItem) {
 	item = NewScheduleItem()
-	now := time.Now()
+	now := now()
 	item.start = &now
 	item.content = content
 	return item
@@ -211,7 +355,7 @@ func ScheduleItemNow(content string) (item *ScheduleItem) {
 
 func ScheduleItemFromString(s string) (item *ScheduleItem,err error) {
 	if itemPattern == nil {
-		pattern,e := regexp.Compile("^(\\d\\d\\d\\d\\.\\d\\d\\.\\d\\d/\\d\\d:\\d\\d) (\\d\\d\\d\\d\\.\\d\\d\\.\\d\\d/\\d\\d:\\d\\d)?( [ -~]*)?$")
+		pattern,e := regexp.Compile("^(\\d\\d\\d\\d\\.\\d\\d\\.\\d\\d/\\d\\d:\\d\\d(?:Z|[+-]\\d\\d:\\d\\d)?) (\\d\\d\\d\\d\\.\\d\\d\\.\\d\\d/\\d\\d:\\d\\d(?:Z|[+-]\\d\\d:\\d\\d)?)?( [^\\p{Cc}]*)?$")
 		if e != nil {
 			return nil,e
 		}
@@ -228,7 +372,7 @@ func ScheduleItemFromString(s string) (item *ScheduleItem,err error) {
 	finishPattern := groups[2]
 	item = NewScheduleItem()
 	content := strings.TrimSpace(groups[3])
-	startTime,e := time.Parse(FORMAT,startPattern)
+	startTime,e := ParseTime(startPattern)
 	if e != nil {
 		return nil,e
 	}
@@ -236,7 +380,7 @@ func ScheduleItemFromString(s string) (item *ScheduleItem,err error) {
 		return nil,errors.New("Invalid start time")
 	}
 	if finishPattern != "" {
-		finishTime,e := time.Parse(FORMAT,finishPattern)
+		finishTime,e := ParseTime(finishPattern)
 		if e != nil {
 			return nil,e
 		}
@@ -248,6 +392,75 @@ func ScheduleItemFromString(s string) (item *ScheduleItem,err error) {
 	return item,nil
 }
 
+// RunningItemFromString reads an item from the start file: the item line,
+// an "until time" line if the finish time is planned, one "break from [to]"
+// line per break, the attribute line and the note lines.
+func RunningItemFromString(s string) (item *ScheduleItem,err error) {
+	if plannedPattern == nil {
+		pattern,e := regexp.Compile("^until (\\d\\d\\d\\d\\.\\d\\d\\.\\d\\d/\\d\\d:\\d\\d(?:Z|[+-]\\d\\d:\\d\\d)?)$")
+		if e != nil {
+			return nil,e
+		}
+		plannedPattern = pattern
+	}
+	if breakPattern == nil {
+		pattern,e := regexp.Compile("^break (\\d\\d\\d\\d\\.\\d\\d\\.\\d\\d/\\d\\d:\\d\\d(?:Z|[+-]\\d\\d:\\d\\d)?)( \\d\\d\\d\\d\\.\\d\\d\\.\\d\\d/\\d\\d:\\d\\d(?:Z|[+-]\\d\\d:\\d\\d)?)?$")
+		if e != nil {
+			return nil,e
+		}
+		breakPattern = pattern
+	}
+	lines := strings.Split(strings.Trim(s,"\n"),"\n")
+	item,err = ScheduleItemFromString(lines[0])
+	if err != nil {
+		return nil,err
+	}
+	lines = lines[1:]
+	if len(lines) > 0 && plannedPattern.MatchString(lines[0]) {
+		plannedTime,e := ParseTime(plannedPattern.FindStringSubmatch(lines[0])[1])
+		if e != nil {
+			return nil,e
+		}
+		if !item.SetPlanned(&plannedTime) {
+			return nil,errors.New("Planned finish time not after start time")
+		}
+		lines = lines[1:]
+	}
+	for _,line := range lines {
+		if IsNoteLine(line) {
+			item.AddNote(NoteOfLine(line))
+			continue
+		}
+		if IsAttributeLine(line) {
+			if e := item.SetAttributeString(AttributesOfLine(line)); e != nil {
+				return nil,e
+			}
+			continue
+		}
+		groups := breakPattern.FindStringSubmatch(line)
+		if groups == nil {
+			return nil,errors.New("Invalid break format: "+line)
+		}
+		pauseTime,e := ParseTime(groups[1])
+		if e != nil {
+			return nil,e
+		}
+		if e = item.Pause(&pauseTime); e != nil {
+			return nil,e
+		}
+		if groups[2] != "" {
+			resumeTime,e := ParseTime(strings.TrimSpace(groups[2]))
+			if e != nil {
+				return nil,e
+			}
+			if e = item.Resume(&resumeTime); e != nil {
+				return nil,e
+			}
+		}
+	}
+	return item,nil
+}
+
 func (item *ScheduleItem) SetContent(content string) {
 	item.content = content
 }
@@ -262,8 +475,8 @@ func (item *ScheduleItem) SetStartFinish(start *time.Time, finish *time.Time) bo
 }
 
 func (item *ScheduleItem) SetStartFinishString(start,finish string) bool {
-	startTime,err1 := time.Parse(FORMAT,start)
-	finishTime,err2 := time.Parse(FORMAT,finish)
+	startTime,err1 := ParseTime(start)
+	finishTime,err2 := ParseTime(finish)
 	if err1 != nil || err2 != nil {
 		return false
 	}
@@ -271,6 +484,12 @@ func (item *ScheduleItem) SetStartFinishString(start,finish string) bool {
 }
 
 func (item *ScheduleItem) SetStart(start *time.Time) bool {
+	if len(item.breaks) > 0 && !item.breaks[0].start.After(*start) {
+		return false
+	}
+	if item.planned != nil && !item.planned.After(*start) {
+		return false
+	}
 	if item.finish == nil || item.finish.After(*start) {
 		item.start = start
 		return true
@@ -279,7 +498,7 @@ func (item *ScheduleItem) SetStart(start *time.Time) bool {
 }
 
 func (item *ScheduleItem) SetStartString(start string) bool {
-	startTime,err := time.Parse(FORMAT,start)
+	startTime,err := ParseTime(start)
 	if err != nil {
 		return false
 	}
@@ -295,7 +514,7 @@ func (item *ScheduleItem) SetFinish(finish *time.Time) bool {
 }
 
 func (item *ScheduleItem) SetFinishString(finish string) bool {
-	finishTime,err := time.Parse(FORMAT,finish)
+	finishTime,err := ParseTime(finish)
 	if err != nil {
 		return false
 	}
@@ -306,37 +525,289 @@ func (item *ScheduleItem) String() string {
 	return fmt.Sprintf("%s %s %s",item.StartString(),item.FinishString(),item.content)
 }
 
+// FullString is the item line followed by the attribute line and the note
+// lines, as written in the day files.
+func (item *ScheduleItem) FullString() string {
+	return item.String()+item.attributeLine()+item.noteString()
+}
+
+func (item *ScheduleItem) noteString() (s string) {
+	for _,line := range item.note {
+		s += "\n"+NOTE_PREFIX+line
+	}
+	return
+}
+
+func IsNoteLine(s string) bool {
+	return strings.HasPrefix(s,strings.TrimSpace(NOTE_PREFIX))
+}
+
+func NoteOfLine(s string) string {
+	return strings.TrimSpace(strings.TrimPrefix(s,strings.TrimSpace(NOTE_PREFIX)))
+}
+
+func IsAttributeLine(s string) bool {
+	return strings.HasPrefix(s,strings.TrimSpace(ATTRIBUTE_PREFIX))
+}
+
+func AttributesOfLine(s string) string {
+	return strings.TrimSpace(strings.TrimPrefix(s,strings.TrimSpace(ATTRIBUTE_PREFIX)))
+}
+
+// SetAttributeString sets the attributes given as space separated
+// key=value pairs. An empty value removes the attribute.
+func (item *ScheduleItem) SetAttributeString(s string) error {
+	for _,pair := range strings.Fields(s) {
+		groups := attributePattern.FindStringSubmatch(pair)
+		if groups == nil {
+			return errors.New("Invalid attribute: "+pair)
+		}
+		item.SetAttribute(groups[1],groups[2])
+	}
+	return nil
+}
+
+func (item *ScheduleItem) SetAttribute(key,value string) {
+	if value == "" {
+		delete(item.attributes,key)
+		return
+	}
+	if item.attributes == nil {
+		item.attributes = make(map[string]string)
+	}
+	item.attributes[key] = value
+}
+
+func (item *ScheduleItem) Attribute(key string) (string,bool) {
+	value,ok := item.attributes[key]
+	return value,ok
+}
+
+// Attributes returns a copy of the attributes of the item.
+func (item *ScheduleItem) Attributes() map[string]string {
+	if len(item.attributes) == 0 {
+		return nil
+	}
+	attributes := make(map[string]string)
+	for key,value := range item.attributes {
+		attributes[key] = value
+	}
+	return attributes
+}
+
+// AttributeString writes the attributes as key=value pairs sorted by key.
+func (item *ScheduleItem) AttributeString() string {
+	keys := make([]string,0,len(item.attributes))
+	for key := range item.attributes {
+		keys = append(keys,key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string,len(keys))
+	for i,key := range keys {
+		pairs[i] = key+"="+item.attributes[key]
+	}
+	return strings.Join(pairs," ")
+}
+
+func (item *ScheduleItem) attributeLine() string {
+	if len(item.attributes) == 0 {
+		return ""
+	}
+	return "\n"+ATTRIBUTE_PREFIX+item.AttributeString()
+}
+
+// AddNote appends the lines of the note to the note of the item.
+func (item *ScheduleItem) AddNote(note string) {
+	for _,line := range strings.Split(note,"\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			item.note = append(item.note,line)
+		}
+	}
+}
+
+func (item *ScheduleItem) Note() string {
+	return strings.Join(item.note,"\n")
+}
+
+func (item *ScheduleItem) PrintNote() {
+	for _,line := range item.note {
+		fmt.Printf("    | %s\n",line)
+	}
+}
+
+func (item *ScheduleItem) RunningString() string {
+	s := item.String()
+	if item.planned != nil {
+		s += fmt.Sprintf("\nuntil %s",FormatTime(*item.planned))
+	}
+	for _,b := range item.breaks {
+		if b.finish == nil {
+			s += fmt.Sprintf("\nbreak %s",FormatTime(*b.start))
+		} else {
+			s += fmt.Sprintf("\nbreak %s %s",FormatTime(*b.start),FormatTime(*b.finish))
+		}
+	}
+	return s+item.attributeLine()+item.noteString()
+}
+
+// SetPlanned sets the time the running item is planned to finish.
+func (item *ScheduleItem) SetPlanned(planned *time.Time) bool {
+	if item.start == nil || !planned.After(*item.start) {
+		return false
+	}
+	item.planned = planned
+	return true
+}
+
+func (item *ScheduleItem) Planned() *time.Time {
+	return item.planned
+}
+
+func (item *ScheduleItem) Paused() bool {
+	return len(item.breaks) > 0 && item.breaks[len(item.breaks)-1].finish == nil
+}
+
+func (item *ScheduleItem) PausedSince() *time.Time {
+	if !item.Paused() {
+		return nil
+	}
+	return item.breaks[len(item.breaks)-1].start
+}
+
+// lastActive is the earliest time a new break may start.
+func (item *ScheduleItem) lastActive() *time.Time {
+	if len(item.breaks) == 0 {
+		return item.start
+	}
+	return item.breaks[len(item.breaks)-1].finish
+}
+
+func (item *ScheduleItem) Pause(t *time.Time) error {
+	if item.start == nil {
+		return errors.New("Empty start time")
+	}
+	if item.Paused() {
+		return errors.New("Already paused since "+FormatTime(*item.PausedSince()))
+	}
+	if !t.After(*item.lastActive()) {
+		return errors.New("Pause time must be after "+FormatTime(*item.lastActive()))
+	}
+	item.breaks = append(item.breaks,&interval{t,nil})
+	return nil
+}
+
+func (item *ScheduleItem) Resume(t *time.Time) error {
+	if !item.Paused() {
+		return errors.New("Not paused")
+	}
+	b := item.breaks[len(item.breaks)-1]
+	if !t.After(*b.start) {
+		return errors.New("Resume time must be after "+FormatTime(*b.start))
+	}
+	b.finish = t
+	return nil
+}
+
+// Segments splits a finished item into the parts that are not in a break.
+// Every part keeps the attributes, and the note goes with the last part.
+func (item *ScheduleItem) Segments() []*ScheduleItem {
+	segments := item.segments()
+	for _,segment := range segments {
+		segment.attributes = item.Attributes()
+	}
+	if len(segments) > 0 {
+		segments[len(segments)-1].note = item.note
+	}
+	return segments
+}
+
+func (item *ScheduleItem) segments() []*ScheduleItem {
+	segments := []*ScheduleItem{}
+	from := item.start
+	for _,b := range item.breaks {
+		if !b.start.Before(*item.finish) {
+			break
+		}
+		segments = item.appendSegment(segments,from,b.start)
+		if b.finish == nil {
+			return segments
+		}
+		from = b.finish
+	}
+	return item.appendSegment(segments,from,item.finish)
+}
+
+func (item *ScheduleItem) appendSegment(segments []*ScheduleItem,from,to *time.Time) []*ScheduleItem {
+	if to.After(*item.finish) {
+		to = item.finish
+	}
+	if !to.After(*from) {
+		return segments
+	}
+	segment := NewScheduleItem()
+	segment.SetStartFinish(from,to)
+	segment.SetContent(item.content)
+	return append(segments,segment)
+}
+
+// breakMinutesWithin counts the minutes of breaks between from and to.
+func (item *ScheduleItem) breakMinutesWithin(from,to *time.Time) int {
+	minute := 0
+	for _,b := range item.breaks {
+		start,finish := b.start,b.finish
+		if finish == nil || finish.After(*to) {
+			finish = to
+		}
+		if start.Before(*from) {
+			start = from
+		}
+		if finish.After(*start) {
+			minute += int(finish.Sub(*start).Minutes())
+		}
+	}
+	return minute
+}
+
 func (item *ScheduleItem) Print() {
 	duration,_ := item.DurationString()
 	duration = fmt.Sprintf("(%s)",duration)
-	fmt.Printf("  From %s to %s %8s: %s\n",
-		item.StartString(),item.FinishString(),duration,item.ContentString())
+	continued := ""
+	if item.continued {
+		continued = " (continued)"
+	}
+	attributes := ""
+	if len(item.attributes) > 0 {
+		attributes = " ["+item.AttributeString()+"]"
+	}
+	fmt.Printf("  From %s to %s %8s: %s%s%s\n",
+		item.StartString(),item.FinishString(),duration,item.ContentString(),attributes,continued)
 }
 
 func (item *ScheduleItem) StartString() string {
 	if item.start != nil {
-		return item.start.Format(FORMAT)
+		return FormatTime(*item.start)
 	}
 	return ""
 }
 
 func (item *ScheduleItem) StartDayString() string {
 	if item.start != nil {
-		return item.start.Format(FORMAT_DAY)
+		return InDayZone(*item.start).Format(FORMAT_DAY)
 	}
 	return ""
 }
 
 func (item *ScheduleItem) FinishString() string {
 	if item.finish != nil {
-		return item.finish.Format(FORMAT)
+		return FormatTime(*item.finish)
 	}
 	return ""
 }
 
 func (item *ScheduleItem) FinishDayString() string {
 	if item.finish != nil {
-		return item.finish.Format(FORMAT_DAY)
+		return InDayZone(*item.finish).Format(FORMAT_DAY)
 	}
 	return ""
 }
@@ -345,6 +816,32 @@ func (item *ScheduleItem) ContentString() string {
 	return item.content
 }
 
+// ParseTags finds the tags in content: words starting with "+", such as
+// +review, or with "@", such as @clientA.
+func ParseTags(content string) []string {
+	if tagPattern == nil {
+		tagPattern = regexp.MustCompile("(?:^|\\s)([+@][^\\s]+)")
+	}
+	tags := []string{}
+	for _,groups := range tagPattern.FindAllStringSubmatch(content,-1) {
+		tags = append(tags,groups[1])
+	}
+	return tags
+}
+
+func (item *ScheduleItem) Tags() []string {
+	return ParseTags(item.content)
+}
+
+func (item *ScheduleItem) HasTag(tag string) bool {
+	for _,t := range item.Tags() {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
 func (item *ScheduleItem) Duration() (int,error) {
 	if item.start == nil {
 		return -1,errors.New("Empty start time")
@@ -353,6 +850,7 @@ func (item *ScheduleItem) Duration() (int,error) {
 		return -1,errors.New("Empty finish time")
 	}
 	minute := int(item.finish.Sub(*item.start).Minutes())
+	minute -= item.breakMinutesWithin(item.start,item.finish)
 	return minute,nil
 }
 
@@ -376,7 +874,7 @@ func (item *ScheduleItem) DurationInDayRange(s,t string) (int,error) {
 
 func (item *ScheduleItem) DurationWithin(from *time.Time, to *time.Time) (int,error) {
 	if from.After(*to) {
-		return -1,errors.New("Invalid range of time: " + from.Format(FORMAT) + " " + to.Format(FORMAT))
+		return -1,errors.New("Invalid range of time: " + FormatTime(*from) + " " + FormatTime(*to))
 	}
 	if item.start.After(*to) {
 		return 0,nil
@@ -391,11 +889,102 @@ func (item *ScheduleItem) DurationWithin(from *time.Time, to *time.Time) (int,er
 		to = item.finish
 	}
 	minute := int(to.Sub(*from).Minutes())
+	minute -= item.breakMinutesWithin(from,to)
 	return minute,nil
 }
 
+// DayPart returns the part of the item within the day, split at midnight,
+// or nil if the item is not in the day.
+func (item *ScheduleItem) DayPart(day string) (*ScheduleItem,error) {
+	if item.start == nil || item.finish == nil {
+		return nil,errors.New("Cannot split unfinished item")
+	}
+	from,to,err := GetRange(day,day)
+	if err != nil {
+		return nil,err
+	}
+	if !item.start.Before(*to) || !item.finish.After(*from) {
+		return nil,nil
+	}
+	if !item.start.Before(*from) && !item.finish.After(*to) {
+		return item,nil
+	}
+	start,finish := item.start,item.finish
+	part := NewScheduleItem()
+	if start.Before(*from) {
+		start = from
+		part.continued = true
+	}
+	if finish.After(*to) {
+		finish = to
+	}
+	part.SetStartFinish(start,finish)
+	part.SetContent(item.content)
+	part.breaks = item.breaks
+	part.attributes = item.Attributes()
+	part.note = item.note
+	return part,nil
+}
+
+// Continued tells whether the item is the part of an item continuing from
+// the day before.
+func (item *ScheduleItem) Continued() bool {
+	return item.continued
+}
+
+func (item *ScheduleItem) Overlaps(other *ScheduleItem) bool {
+	return item.start.Before(*other.finish) && other.start.Before(*item.finish)
+}
+
+// Subtract returns the parts of the item that lie outside other, trimming
+// the item or splitting it in two.
+func (item *ScheduleItem) Subtract(other *ScheduleItem) []*ScheduleItem {
+	if !item.Overlaps(other) {
+		return []*ScheduleItem{item}
+	}
+	parts := []*ScheduleItem{}
+	if item.start.Before(*other.start) {
+		part := NewScheduleItem()
+		part.SetStartFinish(item.start,other.start)
+		part.SetContent(item.content)
+		parts = append(parts,part)
+	}
+	if item.finish.After(*other.finish) {
+		part := NewScheduleItem()
+		part.SetStartFinish(other.finish,item.finish)
+		part.SetContent(item.content)
+		parts = append(parts,part)
+	}
+	for _,part := range parts {
+		part.attributes = item.Attributes()
+	}
+	if len(parts) > 0 {
+		parts[0].note = item.note
+	}
+	return parts
+}
+
+func (item *ScheduleItem) SplitAt(t *time.Time) ([]*ScheduleItem,error) {
+	if item.start == nil || item.finish == nil {
+		return nil,errors.New("Cannot split unfinished item")
+	}
+	if !t.After(*item.start) || !t.Before(*item.finish) {
+		return nil,errors.New("Split time not within item: "+FormatTime(*t))
+	}
+	first := NewScheduleItem()
+	first.SetStartFinish(item.start,t)
+	first.SetContent(item.content)
+	first.attributes = item.Attributes()
+	first.note = item.note
+	second := NewScheduleItem()
+	second.SetStartFinish(t,item.finish)
+	second.SetContent(item.content)
+	second.attributes = item.Attributes()
+	return []*ScheduleItem{first,second},nil
+}
+
 func (item *ScheduleItem) StartDay() *time.Time {
-	t := item.start.Truncate(time.Duration(24)*time.Hour)
+	t := dayOf(*item.start)
 	return &t
 }
 
@@ -454,6 +1043,18 @@ func ScheduleGroupFromFile(filename string) (*ScheduleGroup,error) {
 		if schedule == "" {
 			continue
 		}
+		if IsNoteLine(schedule) || IsAttributeLine(schedule) {
+			if scheduleGroup.Empty() {
+				return nil,errors.New("Line without item: "+schedule)
+			}
+			last := scheduleGroup.items[len(scheduleGroup.items)-1]
+			if IsNoteLine(schedule) {
+				last.AddNote(NoteOfLine(schedule))
+			} else if err := last.SetAttributeString(AttributesOfLine(schedule)); err != nil {
+				return nil,err
+			}
+			continue
+		}
 		err := scheduleGroup.AddString(schedule)
 		if err != nil {
 			return nil,err
@@ -545,6 +1146,14 @@ func (group *ScheduleGroup) RemoveIndex(index int) bool {
 	return true
 }
 
+// Sort orders the items by start time, so that items finished out of order,
+// e.g. from concurrent slots, are still written chronologically.
+func (group *ScheduleGroup) Sort() {
+	sort.SliceStable(group.items,func (i,j int) bool {
+		return group.items[i].start.Before(*group.items[j].start)
+	})
+}
+
 func (group *ScheduleGroup) Print() {
 	for i,item := range(group.items) {
 		fmt.Printf("%3d: %s\n",i+1,item.String())
@@ -554,7 +1163,7 @@ func (group *ScheduleGroup) Print() {
 func (group *ScheduleGroup) String() (s string) {
 	s = ""
 	for _,item := range(group.items) {
-		s += fmt.Sprintf("%s\n",item.String())
+		s += fmt.Sprintf("%s\n",item.FullString())
 	}
 	return
 }
@@ -567,8 +1176,60 @@ func (group *ScheduleGroup) StringOfDay(day string) (s string) {
 	s = ""
 	for _,item := range group.items {
 		if item.StartDayString() == day {
-			s += fmt.Sprintf("%s\n",item.String())
+			s += fmt.Sprintf("%s\n",item.FullString())
 		}
 	}
 	return
 }
+
+/*****************
+ * Display width *
+ *****************/
+
+// wideRanges are the ranges of East Asian wide and fullwidth characters,
+// which take two columns in a terminal.
+var wideRanges = [][2]rune{
+	{0x1100,0x115F},{0x2E80,0x303E},{0x3041,0x33FF},{0x3400,0x4DBF},
+	{0x4E00,0x9FFF},{0xA000,0xA4CF},{0xAC00,0xD7A3},{0xF900,0xFAFF},
+	{0xFE30,0xFE4F},{0xFF00,0xFF60},{0xFFE0,0xFFE6},{0x1F300,0x1F64F},
+	{0x1F900,0x1F9FF},{0x20000,0x2FFFD},{0x30000,0x3FFFD},
+}
+
+func runeWidth(r rune) int {
+	if unicode.Is(unicode.Mn,r) || unicode.Is(unicode.Me,r) || unicode.Is(unicode.Cf,r) {
+		return 0
+	}
+	for _,wide := range wideRanges {
+		if r >= wide[0] && r <= wide[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// DisplayWidth is the number of terminal columns the string takes.
+func DisplayWidth(s string) int {
+	width := 0
+	for _,r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// PadRight pads the string with spaces to the given display width, like
+// the %-*s verb does for ASCII strings.
+func PadRight(s string,width int) string {
+	if w := DisplayWidth(s); w < width {
+		return s+strings.Repeat(" ",width-w)
+	}
+	return s
+}
+
+// PadLeft pads the string with spaces in front to the given display width,
+// like the %*s verb does for ASCII strings.
+func PadLeft(s string,width int) string {
+	if w := DisplayWidth(s); w < width {
+		return strings.Repeat(" ",width-w)+s
+	}
+	return s
+}
@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 	"fmt"
+	"os"
+	"io/ioutil"
 )
 
 func TestGetTimeFuncs(t *testing.T) {
@@ -200,3 +202,317 @@ func TestDurationInDayRange(t *testing.T) {
 		t.Errorf("DurationInDayRange() failed! Expect 0, got %d\n",res4)
 	}
 }
+
+func TestZone(t *testing.T) {
+	defer SetZone("")
+	if err := SetZone("+08:00"); err != nil {
+		t.Fatalf("SetZone() failed! Got error: %s\n",err.Error())
+	}
+	test1 := "2017.03.29/17:32 2017.03.29/17:42-05:00 Java"
+	test1item,err := ScheduleItemFromString(test1)
+	if err != nil {
+		t.Fatalf("ScheduleItemFromString(test1) failed! Got error: %s\n",err.Error())
+	}
+	expect := "2017.03.29/17:32+08:00 2017.03.29/17:42-05:00 Java"
+	result := test1item.String()
+	if result != expect {
+		t.Errorf("item.String() failed! Expect %s, got %s\n",expect,result)
+	}
+	duration,_ := test1item.Duration()
+	if duration != 13*60+10 {
+		t.Errorf("item.Duration() failed! Expect %d, got %d\n",13*60+10,duration)
+	}
+	day := test1item.FinishDayString()
+	if day != "2017.03.30" {
+		t.Errorf("item.FinishDayString() failed! Expect 2017.03.30, got %s\n",day)
+	}
+	res1,_ := test1item.DurationInDay("2017.03.29")
+	if res1 != 6*60+28 {
+		t.Errorf("DurationInDay() failed! Expect %d, got %d\n",6*60+28,res1)
+	}
+	if test1item.StartMinute() != 17*60+32 {
+		t.Errorf("item.StartMinute() failed! Expect %d, got %d\n",17*60+32,test1item.StartMinute())
+	}
+}
+
+func TestNoZone(t *testing.T) {
+	test1 := "2017.03.29/17:32+08:00 2017.03.29/17:42+08:00 Java"
+	test1item,err := ScheduleItemFromString(test1)
+	if err != nil {
+		t.Fatalf("ScheduleItemFromString(test1) failed! Got error: %s\n",err.Error())
+	}
+	expect := "2017.03.29/17:32 2017.03.29/17:42 Java"
+	result := test1item.String()
+	if result != expect {
+		t.Errorf("item.String() failed! Expect %s, got %s\n",expect,result)
+	}
+}
+
+func TestScheduleGroupSort(t *testing.T) {
+	group := NewScheduleGroup()
+	group.AddString("2017.03.29/17:43 2017.03.29/18:12 Review")
+	group.AddString("2017.03.29/17:32 2017.03.29/19:42 Deploy")
+	group.Sort()
+	expect := fmt.Sprintf("%s\n%s\n",
+		"2017.03.29/17:32 2017.03.29/19:42 Deploy",
+		"2017.03.29/17:43 2017.03.29/18:12 Review")
+	result := group.String()
+	if result != expect {
+		t.Errorf("group.Sort() failed! Expect %s, got %s\n",expect,result)
+	}
+}
+
+func TestBreaks(t *testing.T) {
+	test1 := "2017.03.29/17:32  Java\nbreak 2017.03.29/18:00 2017.03.29/18:30\nbreak 2017.03.29/19:00"
+	test1item,err := RunningItemFromString(test1)
+	if err != nil {
+		t.Fatalf("RunningItemFromString(test1) failed! Got error: %s\n",err.Error())
+	}
+	if !test1item.Paused() {
+		t.Errorf("item.Paused() failed! Expect true, got false\n")
+	}
+	result := test1item.RunningString()
+	if result != test1 {
+		t.Errorf("item.RunningString() failed! Expect %s, got %s\n",test1,result)
+	}
+	if !test1item.SetFinishString("2017.03.29/19:20") {
+		t.Fatalf("item.SetFinishString() failed!\n")
+	}
+	duration,_ := test1item.Duration()
+	if duration != 58 {
+		t.Errorf("item.Duration() failed! Expect 58, got %d\n",duration)
+	}
+	from,_ := GetTime("2017.03.29/18:15")
+	to,_ := GetTime("2017.03.29/19:10")
+	within,_ := test1item.DurationWithin(from,to)
+	if within != 30 {
+		t.Errorf("item.DurationWithin() failed! Expect 30, got %d\n",within)
+	}
+	segments := test1item.Segments()
+	group := NewScheduleGroup()
+	for _,segment := range segments {
+		group.Add(segment)
+	}
+	expect := fmt.Sprintf("%s\n%s\n",
+		"2017.03.29/17:32 2017.03.29/18:00 Java",
+		"2017.03.29/18:30 2017.03.29/19:00 Java")
+	if group.String() != expect {
+		t.Errorf("item.Segments() failed! Expect %s, got %s\n",expect,group.String())
+	}
+}
+
+func TestPlanned(t *testing.T) {
+	test1 := "2017.03.29/17:32  Java\nuntil 2017.03.29/18:17\nbreak 2017.03.29/18:00"
+	test1item,err := RunningItemFromString(test1)
+	if err != nil {
+		t.Fatalf("RunningItemFromString(test1) failed! Got error: %s\n",err.Error())
+	}
+	planned := test1item.Planned()
+	if planned == nil || FormatTime(*planned) != "2017.03.29/18:17" {
+		t.Errorf("item.Planned() failed! Expect 2017.03.29/18:17, got %v\n",planned)
+	}
+	if !test1item.Paused() {
+		t.Errorf("item.Paused() failed! Expect true, got false\n")
+	}
+	result := test1item.RunningString()
+	if result != test1 {
+		t.Errorf("item.RunningString() failed! Expect %s, got %s\n",test1,result)
+	}
+	test2 := "2017.03.29/17:32  Java\nuntil 2017.03.29/17:00"
+	_,err = RunningItemFromString(test2)
+	if err == nil {
+		t.Errorf("RunningItemFromString(test2) should fail on planned time before start\n")
+	}
+	test3item,_ := RunningItemFromString("2017.03.29/05:00  Java\nuntil 2017.03.29/05:30")
+	if test3item.SetStartString("2017.03.29/06:00") || test3item.SetStartString("2017.03.29/05:30") {
+		t.Errorf("item.SetStart() should fail on start at or after planned time\n")
+	}
+	if !test3item.SetStartString("2017.03.29/05:10") {
+		t.Errorf("item.SetStart() failed on start before planned time\n")
+	}
+	if _,err = RunningItemFromString(test3item.RunningString()); err != nil {
+		t.Errorf("RunningItemFromString(test3) failed! Got error: %s\n",err.Error())
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	item,_ := ScheduleItemFromString("2017.03.29/17:00 2017.03.29/19:00 Java")
+	inner,_ := ScheduleItemFromString("2017.03.29/17:30 2017.03.29/18:00 Lunch")
+	outer,_ := ScheduleItemFromString("2017.03.29/16:00 2017.03.29/20:00 Meeting")
+	after,_ := ScheduleItemFromString("2017.03.29/19:00 2017.03.29/20:00 Read")
+	if !item.Overlaps(inner) || !item.Overlaps(outer) || item.Overlaps(after) {
+		t.Errorf("item.Overlaps() failed!\n")
+	}
+	parts := item.Subtract(inner)
+	if len(parts) != 2 {
+		t.Fatalf("item.Subtract() failed! Expect 2 parts, got %d\n",len(parts))
+	}
+	expect1 := "2017.03.29/17:00 2017.03.29/17:30 Java"
+	expect2 := "2017.03.29/18:00 2017.03.29/19:00 Java"
+	if parts[0].String() != expect1 || parts[1].String() != expect2 {
+		t.Errorf("item.Subtract() failed! Expect %s and %s, got %s and %s\n",
+			expect1,expect2,parts[0].String(),parts[1].String())
+	}
+	if len(item.Subtract(outer)) != 0 {
+		t.Errorf("item.Subtract() failed! Expect no parts\n")
+	}
+	parts = item.Subtract(after)
+	if len(parts) != 1 || parts[0] != item {
+		t.Errorf("item.Subtract() failed! Expect the item itself\n")
+	}
+}
+
+func TestSplitAt(t *testing.T) {
+	item,_ := ScheduleItemFromString("2017.03.29/17:00 2017.03.29/19:00 Java")
+	at,_ := GetTime("2017.03.29/18:10")
+	parts,err := item.SplitAt(at)
+	if err != nil {
+		t.Fatalf("item.SplitAt() failed! Got error: %s\n",err.Error())
+	}
+	expect1 := "2017.03.29/17:00 2017.03.29/18:10 Java"
+	expect2 := "2017.03.29/18:10 2017.03.29/19:00 Java"
+	if parts[0].String() != expect1 || parts[1].String() != expect2 {
+		t.Errorf("item.SplitAt() failed! Expect %s and %s, got %s and %s\n",
+			expect1,expect2,parts[0].String(),parts[1].String())
+	}
+	outside,_ := GetTime("2017.03.29/19:00")
+	if _,err := item.SplitAt(outside); err == nil {
+		t.Errorf("item.SplitAt() failed! Expect error for time at finish\n")
+	}
+}
+
+func TestDayPart(t *testing.T) {
+	item,_ := ScheduleItemFromString("2017.03.28/23:32 2017.03.29/00:44 Java")
+	part1,err := item.DayPart("2017.03.28")
+	if err != nil {
+		t.Fatalf("item.DayPart() failed! Got error: %s\n",err.Error())
+	}
+	part2,_ := item.DayPart("2017.03.29")
+	part3,_ := item.DayPart("2017.03.30")
+	expect1 := "2017.03.28/23:32 2017.03.29/00:00 Java"
+	expect2 := "2017.03.29/00:00 2017.03.29/00:44 Java"
+	if part1.String() != expect1 || part1.Continued() {
+		t.Errorf("item.DayPart() failed! Expect %s, got %s\n",expect1,part1.String())
+	}
+	if part2.String() != expect2 || !part2.Continued() {
+		t.Errorf("item.DayPart() failed! Expect continued %s, got %s\n",expect2,part2.String())
+	}
+	if part3 != nil {
+		t.Errorf("item.DayPart() failed! Expect nil, got %s\n",part3.String())
+	}
+	if part2.StartMinute() != 0 || part2.FinishMinute() != 44 {
+		t.Errorf("item.DayPart() failed! Expect minutes 0 to 44, got %d to %d\n",
+			part2.StartMinute(),part2.FinishMinute())
+	}
+}
+
+func TestTags(t *testing.T) {
+	item,_ := ScheduleItemFromString("2017.03.29/17:32 2017.03.29/17:42 +review design doc @clientA C++ a+b")
+	tags := item.Tags()
+	if len(tags) != 2 || tags[0] != "+review" || tags[1] != "@clientA" {
+		t.Errorf("item.Tags() failed! Expect [+review @clientA], got %v\n",tags)
+	}
+	if !item.HasTag("@clientA") || item.HasTag("+clientA") {
+		t.Errorf("item.HasTag() failed!\n")
+	}
+}
+
+func TestUnicode(t *testing.T) {
+	test1 := "2017.03.29/17:32 2017.03.29/18:00 写代码 +开发"
+	test1item,err := ScheduleItemFromString(test1)
+	if err != nil {
+		t.Fatalf("ScheduleItemFromString(test1) failed! Got error: %s\n",err.Error())
+	}
+	if test1item.ContentString() != "写代码 +开发" {
+		t.Errorf("item.ContentString() failed! Expect 写代码 +开发, got %s\n",test1item.ContentString())
+	}
+	if !test1item.HasTag("+开发") {
+		t.Errorf("item.HasTag() failed! Expect true, got false\n")
+	}
+	if test1item.String() != test1 {
+		t.Errorf("item.String() failed! Expect %s, got %s\n",test1,test1item.String())
+	}
+	width := DisplayWidth("写代码 Java")
+	if width != 11 {
+		t.Errorf("DisplayWidth() failed! Expect 11, got %d\n",width)
+	}
+	padded := PadRight("写代码",8)
+	if padded != "写代码  " {
+		t.Errorf("PadRight() failed! Expect '写代码  ', got '%s'\n",padded)
+	}
+	padded = PadLeft("写代码",8)
+	if padded != "  写代码" {
+		t.Errorf("PadLeft() failed! Expect '  写代码', got '%s'\n",padded)
+	}
+	_,err = ScheduleItemFromString("2017.03.29/17:32 2017.03.29/18:00 tab\tcontent")
+	if err == nil {
+		t.Errorf("ScheduleItemFromString() should fail on control characters\n")
+	}
+}
+
+func TestNote(t *testing.T) {
+	test1 := "2017.03.29/17:32  Java\nbreak 2017.03.29/18:00 2017.03.29/18:30\n> fixed flaky test\n> reviewed"
+	test1item,err := RunningItemFromString(test1)
+	if err != nil {
+		t.Fatalf("RunningItemFromString(test1) failed! Got error: %s\n",err.Error())
+	}
+	if test1item.Note() != "fixed flaky test\nreviewed" {
+		t.Errorf("item.Note() failed! Expect two lines, got %s\n",test1item.Note())
+	}
+	result := test1item.RunningString()
+	if result != test1 {
+		t.Errorf("item.RunningString() failed! Expect %s, got %s\n",test1,result)
+	}
+	test1item.SetFinishString("2017.03.29/19:00")
+	segments := test1item.Segments()
+	if len(segments) != 2 || segments[0].Note() != "" || segments[1].Note() != test1item.Note() {
+		t.Errorf("item.Segments() failed! Expect the note on the last segment\n")
+	}
+	file,err := ioutil.TempFile("","daylog")
+	if err != nil {
+		t.Fatalf("TempFile() failed! Got error: %s\n",err.Error())
+	}
+	defer os.Remove(file.Name())
+	content := "2017.03.29/17:32 2017.03.29/18:00 Java\n> fixed flaky test\n2017.03.29/18:30 2017.03.29/19:00 Java\n"
+	file.WriteString(content)
+	file.Close()
+	group,err := ScheduleGroupFromFile(file.Name())
+	if err != nil {
+		t.Fatalf("ScheduleGroupFromFile() failed! Got error: %s\n",err.Error())
+	}
+	if group.Size() != 2 {
+		t.Errorf("ScheduleGroupFromFile() failed! Expect 2 items, got %d\n",group.Size())
+	}
+	if group.String() != content {
+		t.Errorf("group.String() failed! Expect %s, got %s\n",content,group.String())
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	test1 := "2017.03.29/17:32  Java\nbreak 2017.03.29/18:00 2017.03.29/18:30\n: energy=3 location=office\n> fixed flaky test"
+	test1item,err := RunningItemFromString(test1)
+	if err != nil {
+		t.Fatalf("RunningItemFromString(test1) failed! Got error: %s\n",err.Error())
+	}
+	energy,ok := test1item.Attribute("energy")
+	if !ok || energy != "3" {
+		t.Errorf("item.Attribute() failed! Expect 3, got %s\n",energy)
+	}
+	result := test1item.RunningString()
+	if result != test1 {
+		t.Errorf("item.RunningString() failed! Expect %s, got %s\n",test1,result)
+	}
+	test1item.SetAttributeString("location= billable=yes")
+	if test1item.AttributeString() != "billable=yes energy=3" {
+		t.Errorf("item.AttributeString() failed! Expect billable=yes energy=3, got %s\n",test1item.AttributeString())
+	}
+	test1item.SetFinishString("2017.03.29/19:00")
+	for _,segment := range test1item.Segments() {
+		if segment.AttributeString() != test1item.AttributeString() {
+			t.Errorf("item.Segments() failed! Expect the attributes on every segment\n")
+		}
+	}
+	if test1item.SetAttributeString("energy") == nil {
+		t.Errorf("item.SetAttributeString() should fail without a value\n")
+	}
+}